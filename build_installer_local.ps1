@@ -1,11 +1,15 @@
 $ErrorActionPreference = "Stop"
 
-Write-Host "Building jvt.exe..."
+# The installer script is the source of the version for local builds
+$version = (Select-String -Path installer/jvt.iss -Pattern '#define MyAppVersion "(.+)"').Matches[0].Groups[1].Value
+if (-not $version) { throw "MyAppVersion not found in installer/jvt.iss" }
+
+Write-Host "Building jvt.exe $version..."
 if (-not (Test-Path "build")) {
     New-Item -ItemType Directory -Force -Path "build" | Out-Null
 }
 
-go build -o build/jvt.exe cmd/jvt/main.go
+go build -ldflags "-X main.Version=$version" -o build/jvt.exe cmd/jvt/main.go
 if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }
 
 Write-Host "Building Installer..."
@@ -1,15 +1,19 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/rexqwer911/jvt/internal/cli"
 )
 
+// Version is the jvt version; release builds set it with
+// -ldflags "-X main.Version=<version>"
+var Version = "dev"
+
 func main() {
+	cli.SetVersion(Version)
 	if err := cli.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		cli.PrintError(err)
+		os.Exit(cli.ExitCode(err))
 	}
 }
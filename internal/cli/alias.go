@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// aliasNamePattern keeps alias names distinct from version specs, which start with a digit
+var aliasNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage named aliases for installed versions",
+	Long: `Give installed versions names such as "prod" that use, exec, uninstall and
+project files accept wherever a version is expected. When the version behind a
+name changes, only the alias needs to be updated.
+
+Examples:
+  jvt alias set prod 17.0.10+7   # Point prod at Java 17.0.10+7
+  jvt use prod                   # Switch to the version prod points at
+  jvt alias ls                   # List aliases
+  jvt alias rm prod              # Remove the alias`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <version>",
+	Short: "Create or move an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, target := args[0], expandAlias(args[1])
+
+		if !aliasNamePattern.MatchString(name) {
+			return usageErrorf("invalid alias name %q: it must start with a letter and contain only letters, digits, '.', '_' and '-'", name)
+		}
+
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		// An alias must name something that can be used right away
+		matchedVersion, err := resolveInstalled(install.NewInstaller(cfg.InstallDir), target)
+		if err != nil {
+			return err
+		}
+
+		var previous string
+		err = state.Update(cmd.Context(), cfg.StateFile, func(s *state.State) error {
+			if s.Aliases == nil {
+				s.Aliases = map[string]string{}
+			}
+			previous = s.Aliases[name]
+			s.Aliases[name] = target
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save alias: %w", err)
+		}
+
+		if previous != "" && previous != target {
+			fmt.Printf("✓ %s → %s (was %s)\n", name, target, previous)
+		} else {
+			fmt.Printf("✓ %s → %s\n", name, target)
+		}
+		if matchedVersion != target {
+			fmt.Printf("  currently Java %s\n", matchedVersion)
+		}
+
+		return emit(aliasEntry{Name: name, Target: target, Version: matchedVersion})
+	},
+}
+
+var aliasLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List aliases and the versions they point at",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		s, err := state.Load(cfg.StateFile)
+		if err != nil {
+			return err
+		}
+
+		installer := install.NewInstaller(cfg.InstallDir)
+		list := aliasList{Aliases: []aliasEntry{}}
+		for _, name := range aliasNames(s.Aliases) {
+			entry := aliasEntry{Name: name, Target: s.Aliases[name]}
+			entry.Version, _ = resolveInstalled(installer, entry.Target)
+			list.Aliases = append(list.Aliases, entry)
+		}
+
+		if structuredOutput() {
+			return emit(list)
+		}
+
+		if len(list.Aliases) == 0 {
+			fmt.Println("No aliases defined.")
+			fmt.Println("Use 'jvt alias set <name> <version>' to create one.")
+			return nil
+		}
+
+		for _, entry := range list.Aliases {
+			switch entry.Version {
+			case "":
+				fmt.Printf("  %s → %s (not installed)\n", entry.Name, entry.Target)
+			case entry.Target:
+				fmt.Printf("  %s → %s\n", entry.Name, entry.Target)
+			default:
+				fmt.Printf("  %s → %s (Java %s)\n", entry.Name, entry.Target, entry.Version)
+			}
+		}
+		return nil
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove"},
+	Short:   "Remove an alias",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		var target string
+		err = state.Update(cmd.Context(), cfg.StateFile, func(s *state.State) error {
+			var ok bool
+			if target, ok = s.Aliases[name]; !ok {
+				return fmt.Errorf("alias %q does not exist", name)
+			}
+			delete(s.Aliases, name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Alias %s removed (was %s)\n", name, target)
+		return emit(aliasEntry{Name: name, Target: target})
+	},
+}
+
+// aliasEntry is the structured form of an alias; Version is the installed
+// version it currently resolves to, if any
+type aliasEntry struct {
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	Version string `json:"version,omitempty"`
+}
+
+// aliasList is the structured output of alias ls
+type aliasList struct {
+	Aliases []aliasEntry `json:"aliases"`
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasLsCmd)
+	aliasCmd.AddCommand(aliasRmCmd)
+}
+
+// aliasNames returns the names of aliases in sorted order
+func aliasNames(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
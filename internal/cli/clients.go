@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/download"
+	"github.com/rexqwer911/jvt/internal/registry"
+)
+
+var (
+	offlineMode  bool
+	refreshCache bool
+)
+
+// newRegistry creates a registry backed by the on-disk catalogue cache,
+// honouring the --offline and --refresh flags
+func newRegistry(cfg *config.Config) *registry.Registry {
+	mode := registry.CacheDefault
+	if offlineMode {
+		mode = registry.CacheOffline
+	} else if refreshCache {
+		mode = registry.CacheRefresh
+	}
+
+	reg := registry.NewRegistry()
+	reg.EnableCache(cfg.RegistryCacheFile, cfg.RegistryCacheTTL, mode)
+	return reg
+}
+
+// newDownloader creates a downloader honouring the --offline flag
+func newDownloader(cfg *config.Config) *download.Downloader {
+	downloader := download.NewDownloader(cfg.CacheDir)
+	downloader.SetOffline(offlineMode)
+	return downloader
+}
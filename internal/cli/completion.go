@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/rexqwer911/jvt/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// isCompletionRequest reports whether cmd is cobra's hidden completion command
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd
+}
+
+// setupCompletion keeps stray messages from corrupting completion results,
+// which cobra writes to the command output
+func setupCompletion(cmd *cobra.Command) {
+	cmd.Root().SetOut(stdout)
+	os.Stdout = os.Stderr
+}
+
+// completeInstalledVersions completes the first argument with installed versions
+func completeInstalledVersions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return installedCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeExec completes the version of exec, then falls back to file
+// completion for the command to run
+func completeExec(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return installedCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInstalledMajors completes the first argument with installed major versions
+func completeInstalledMajors(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	majors, err := install.NewInstaller(cfg.InstallDir).GetInstalledMajorVersions()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]string, len(majors))
+	for idx, major := range majors {
+		completions[idx] = strconv.Itoa(major)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRemoteVersions completes install with the majors and versions of the
+// cached catalogue, described by their distribution. It never touches the
+// network, so nothing is offered until the catalogue has been fetched once.
+func completeRemoteVersions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	reg := registry.NewRegistry()
+	reg.EnableCache(cfg.RegistryCacheFile, cfg.RegistryCacheTTL, registry.CacheOffline)
+	if err := reg.FetchAvailableVersions(context.Background()); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	seenMajor := map[int]bool{}
+	for _, v := range reg.GetVersions() {
+		if !seenMajor[v.MajorVersion] {
+			seenMajor[v.MajorVersion] = true
+			completions = append(completions, fmt.Sprintf("%d\t%s %s", v.MajorVersion, v.Distribution, v.Version))
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%s", v.Version, v.Distribution))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// installedCompletions returns the installed versions, described by their
+// distribution and whether they are current or the default, and the aliases
+func installedCompletions() []string {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil
+	}
+
+	installer := install.NewInstaller(cfg.InstallDir)
+	versions, err := installer.ListInstalled()
+	if err != nil {
+		return nil
+	}
+
+	mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+	currentVersion, _ := mgr.GetCurrentVersion()
+	defaultVersion, _ := mgr.GetDefaultVersion()
+
+	completions := make([]string, 0, len(versions))
+	for _, v := range versions {
+		description := "installed"
+		if meta, err := installer.ReadMetadata(v); err == nil && meta.Distribution != "" {
+			description = meta.Distribution
+		}
+		switch v {
+		case currentVersion:
+			description += ", current"
+		case defaultVersion:
+			description += ", default"
+		}
+		completions = append(completions, v+"\t"+description)
+	}
+
+	if s, err := state.Load(cfg.StateFile); err == nil {
+		for _, name := range aliasNames(s.Aliases) {
+			completions = append(completions, name+"\talias for "+s.Aliases[name])
+		}
+	}
+	return completions
+}
+
+// completeAliasSet completes the version of alias set
+func completeAliasSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return installedCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAliasNames completes the first argument with the defined aliases
+func completeAliasNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	s, err := state.Load(cfg.StateFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, name := range aliasNames(s.Aliases) {
+		completions = append(completions, name+"\t"+s.Aliases[name])
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePinned completes the first argument with the pinned builds
+func completePinned(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	s, err := state.Load(cfg.StateFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return s.Pinned, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOutputFormat completes the values of --output
+func completeOutputFormat(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{
+		outputTable + "\tHuman-readable text",
+		outputJSON + "\tJSON document",
+		outputYAML + "\tYAML document",
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	useCmd.ValidArgsFunction = completeInstalledVersions
+	uninstallCmd.ValidArgsFunction = completeInstalledVersions
+	infoCmd.ValidArgsFunction = completeInstalledVersions
+	verifyCmd.ValidArgsFunction = completeInstalledVersions
+	repairCmd.ValidArgsFunction = completeInstalledVersions
+	execCmd.ValidArgsFunction = completeExec
+	upgradeCmd.ValidArgsFunction = completeInstalledMajors
+	installCmd.ValidArgsFunction = completeRemoteVersions
+	lockCmd.ValidArgsFunction = completeRemoteVersions
+	aliasSetCmd.ValidArgsFunction = completeAliasSet
+	pinCmd.ValidArgsFunction = completeInstalledVersions
+	unpinCmd.ValidArgsFunction = completePinned
+	aliasRmCmd.ValidArgsFunction = completeAliasNames
+
+	// Commands without arguments shouldn't offer file names
+	for _, cmd := range []*cobra.Command{listCmd, listRemoteCmd, currentCmd, doctorCmd, syncCmd, exportCmd, aliasLsCmd} {
+		cmd.ValidArgsFunction = cobra.NoFileCompletions
+	}
+}
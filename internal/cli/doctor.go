@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with the jvt environment",
+	Long: `Check PATH, JAVA_HOME, shell integration, installed versions and the
+download cache for common problems, and suggest how to fix them.
+
+Examples:
+  jvt doctor        # Report problems
+  jvt doctor --fix  # Also apply the fixes that are safe to automate`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		findings := doctor.New(cfg).Run()
+
+		failed := 0
+		for _, f := range findings {
+			switch f.Status {
+			case doctor.StatusPass:
+				fmt.Printf("✓ %s: %s\n", f.Check, f.Message)
+				continue
+			case doctor.StatusWarn:
+				fmt.Printf("! %s: %s\n", f.Check, f.Message)
+			default:
+				fmt.Printf("✗ %s: %s\n", f.Check, f.Message)
+			}
+
+			if doctorFix && f.Fixable {
+				if err := f.Fix(cmd.Context()); err != nil {
+					fmt.Printf("    Fix failed: %v\n", err)
+				} else {
+					fmt.Println("    Fixed.")
+					continue
+				}
+			} else if f.Suggestion != "" {
+				fmt.Printf("    Suggestion: %s\n", f.Suggestion)
+				if f.Fixable {
+					fmt.Println("    (run 'jvt doctor --fix' to apply)")
+				}
+			}
+
+			if f.Status == doctor.StatusFail {
+				failed++
+			}
+		}
+
+		if err := emit(doctorReport{Checks: findings}); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+
+		return nil
+	},
+}
+
+// doctorReport is the structured output of doctor
+type doctorReport struct {
+	Checks []*doctor.Finding `json:"checks"`
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply safe fixes automatically")
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <version> -- <command> [args...]",
+	Short: "Run a command with a specific Java version",
+	Long: `Run a single command with JAVA_HOME and PATH pointing at an installed
+Java version, without changing the active version.
+
+Examples:
+  jvt exec 17 -- java -version
+  jvt exec 21 -- ./gradlew build`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		installer := install.NewInstaller(cfg.InstallDir)
+		matchedVersion, err := resolveInstalled(installer, args[0])
+		if err != nil {
+			return err
+		}
+
+		javaHome := installer.GetJavaHome(matchedVersion)
+		javaBin := filepath.Join(javaHome, "bin")
+
+		env := append(os.Environ(),
+			"JAVA_HOME="+javaHome,
+			"PATH="+javaBin+string(os.PathListSeparator)+os.Getenv("PATH"),
+		)
+
+		// Resolve the command against the new PATH so "java" picks the requested version
+		name := args[1]
+		if filepath.Base(name) == name {
+			if path, err := lookPathIn(name, javaBin); err == nil {
+				name = path
+			}
+		}
+
+		child := exec.Command(name, args[2:]...)
+		child.Env = env
+		child.Stdin = os.Stdin
+		child.Stdout = stdout
+		child.Stderr = os.Stderr
+
+		if err := child.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				// Propagate the child's exit code as-is
+				return &commandExitError{code: exitErr.ExitCode()}
+			}
+			return fmt.Errorf("failed to run %s: %w", args[1], err)
+		}
+
+		return nil
+	},
+}
+
+// commandExitError is returned when the command run by exec fails. jvt exits
+// with the command's exit code and prints nothing, as the command has already
+// reported the problem.
+type commandExitError struct {
+	code int
+}
+
+func (e *commandExitError) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.code)
+}
+
+// lookPathIn looks for an executable in a single directory
+func lookPathIn(name, dir string) (string, error) {
+	candidates := []string{filepath.Join(dir, name)}
+	if filepath.Ext(name) == "" {
+		candidates = append(candidates, filepath.Join(dir, name+".exe"))
+	}
+
+	for _, c := range candidates {
+		if fi, err := os.Stat(c); err == nil && !fi.IsDir() {
+			return c, nil
+		}
+	}
+
+	return exec.LookPath(name)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/rexqwer911/jvt/internal/download"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// Exit codes returned by jvt. They are part of the documented interface, so
+// existing values must never change meaning.
+const (
+	ExitOK               = 0
+	ExitError            = 1
+	ExitUsage            = 2
+	ExitNotFound         = 3
+	ExitChecksum         = 4
+	ExitNetwork          = 5
+	ExitPermission       = 6
+	ExitAlreadyInstalled = 7
+	ExitAmbiguous        = 8
+	ExitInterrupted      = 130
+)
+
+// usageError is returned for invalid command lines
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usageErrorf creates a usage error with a formatted message
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// commandStarted is set once the command line has been parsed and validated.
+// Errors returned before that point come from cobra and are usage errors.
+var commandStarted bool
+
+// classifyError returns the exit code and a stable machine-readable kind for err
+func classifyError(err error) (int, string) {
+	var (
+		usageErr        *usageError
+		commandExitErr  *commandExitError
+		ambiguousErr    *version.AmbiguousVersionError
+		checksumErr     *download.ChecksumError
+		alreadyErr      *install.AlreadyInstalledError
+		notInstalledErr *install.NotInstalledError
+		notMatchedErr   *version.NotInstalledError
+		notFoundErr     *registry.NotFoundError
+		registryNetErr  *registry.NetworkError
+		downloadNetErr  *download.NetworkError
+	)
+
+	switch {
+	case errors.As(err, &usageErr) || !commandStarted:
+		return ExitUsage, "usage"
+	case errors.As(err, &commandExitErr):
+		return commandExitErr.code, "command_failed"
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted, "interrupted"
+	case errors.As(err, &ambiguousErr):
+		return ExitAmbiguous, "ambiguous"
+	case errors.As(err, &checksumErr):
+		return ExitChecksum, "checksum_mismatch"
+	case errors.As(err, &alreadyErr):
+		return ExitAlreadyInstalled, "already_installed"
+	case errors.As(err, &notInstalledErr), errors.As(err, &notMatchedErr), errors.As(err, &notFoundErr):
+		return ExitNotFound, "not_found"
+	case errors.Is(err, fs.ErrPermission):
+		return ExitPermission, "permission"
+	case errors.As(err, &registryNetErr), errors.As(err, &downloadNetErr), errors.Is(err, context.DeadlineExceeded):
+		return ExitNetwork, "network"
+	default:
+		return ExitError, "error"
+	}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	code, _ := classifyError(err)
+	return code
+}
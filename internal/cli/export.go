@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/inventory"
+	"github.com/rexqwer911/jvt/internal/project"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/rexqwer911/jvt/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var importDryRun bool
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write the installed JDKs as an inventory for 'jvt import'",
+	Long: `Write the installed versions with their distributions and checksums, the
+default version and the aliases to stdout. The inventory is always JSON.
+
+Examples:
+  jvt export > jdks.json   # Save the inventory
+  jvt import jdks.json     # Reproduce it on another machine`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		s, err := state.Load(cfg.StateFile)
+		if err != nil {
+			return err
+		}
+
+		inv, err := inventory.Collect(install.NewInstaller(cfg.InstallDir), version.NewManager(cfg.InstallDir, cfg.StateFile), s.Aliases)
+		if err != nil {
+			return err
+		}
+
+		emitted = true
+		enc := json.NewEncoder(stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(inv)
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Install the JDKs listed in an inventory from 'jvt export'",
+	Long: `Install every version of an inventory written by 'jvt export' that is not
+installed yet, downloading in parallel, and restore the exported default version
+and aliases.
+
+Builds are looked up in the version catalogue for this platform. A version that
+is no longer in the catalogue is downloaded from its exported URL if the
+inventory comes from the same platform, and verified against the exported
+checksum. Use - to read the inventory from stdin.
+
+Examples:
+  jvt import jdks.json            # Install what jdks.json lists
+  jvt import --dry-run jdks.json  # Only report what is missing`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		if err := cfg.EnsureDirectories(); err != nil {
+			return fmt.Errorf("failed to create directories: %w", err)
+		}
+
+		inv, err := readInventory(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Importing %d JDK(s)\n", len(inv.Versions))
+
+		installer := install.NewInstaller(cfg.InstallDir)
+		report := importReport{Versions: make([]importResult, len(inv.Versions))}
+
+		var pending []int
+		for idx, entry := range inv.Versions {
+			result := &report.Versions[idx]
+			result.Version = entry.Version
+			result.Distribution = entry.Distribution
+
+			if !installer.IsInstalled(entry.Version) {
+				pending = append(pending, idx)
+				continue
+			}
+
+			result.Status = "ok"
+			if meta, err := installer.ReadMetadata(entry.Version); err == nil && sameBuildPlatform(meta, entry) &&
+				entry.Checksum != "" && !strings.EqualFold(meta.Checksum, entry.Checksum) {
+				result.Status = "different-build"
+			}
+		}
+
+		if len(pending) > 0 {
+			fmt.Println("Fetching available versions...")
+			reg := newRegistry(cfg)
+			if err := reg.FetchAvailableVersions(cmd.Context()); err != nil {
+				return fmt.Errorf("failed to fetch versions: %w", err)
+			}
+
+			var versions []*registry.JavaVersion
+			var indices []int
+			for _, idx := range pending {
+				v, err := importBuild(reg, inv.Versions[idx])
+				switch {
+				case err != nil:
+					report.Versions[idx].fail(err)
+				case importDryRun:
+					report.Versions[idx].Status = "missing"
+				default:
+					versions = append(versions, v)
+					indices = append(indices, idx)
+				}
+			}
+
+			errs := installConcurrently(cmd.Context(), cfg, installer, versions)
+			for pos, idx := range indices {
+				if errs[pos] != nil {
+					report.Versions[idx].fail(errs[pos])
+				} else {
+					report.Versions[idx].Status = "installed"
+				}
+			}
+			if err := cmd.Context().Err(); err != nil {
+				return err
+			}
+		}
+
+		var firstErr error
+		failed := 0
+		for _, r := range report.Versions {
+			switch r.Status {
+			case "ok":
+				fmt.Printf("✓ Java %s (already installed)\n", r.Version)
+			case "different-build":
+				fmt.Printf("! Java %s is installed from a different build than the exported one\n", r.Version)
+			case "installed":
+				fmt.Printf("✓ Java %s installed\n", r.Version)
+			case "missing":
+				fmt.Printf("+ Java %s would be installed\n", r.Version)
+			default:
+				fmt.Printf("✗ Java %s: %s\n", r.Version, r.Error)
+				failed++
+				if firstErr == nil {
+					firstErr = r.err
+				}
+			}
+		}
+
+		if err := restoreDefault(cmd.Context(), cfg, installer, inv.Default, &report); err != nil {
+			return err
+		}
+
+		if err := restoreAliases(cmd.Context(), cfg, inv.Aliases, &report); err != nil {
+			return err
+		}
+
+		if err := emit(report); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d JDK(s) could not be imported: %w", failed, len(report.Versions), firstErr)
+		}
+
+		return nil
+	},
+}
+
+// importReport is the structured output of import
+type importReport struct {
+	Versions []importResult    `json:"versions"`
+	Default  string            `json:"default,omitempty"`
+	Aliases  map[string]string `json:"aliases,omitempty"`
+}
+
+// importResult is the outcome for one version of the inventory
+type importResult struct {
+	Version      string `json:"version"`
+	Distribution string `json:"distribution,omitempty"`
+	// Status is ok, different-build, installed, missing (with --dry-run) or failed
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	err error
+}
+
+// fail records why a version could not be imported
+func (r *importResult) fail(err error) {
+	r.Status = "failed"
+	r.Error = err.Error()
+	r.err = err
+}
+
+func init() {
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Report what would be installed without installing anything")
+}
+
+// readInventory reads an inventory from a file, or from stdin for "-"
+func readInventory(path string) (*inventory.Inventory, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	inv, err := inventory.Read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Imported aliases must not shadow version specs, like those set with 'jvt alias set'
+	for _, name := range aliasNames(inv.Aliases) {
+		if !aliasNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid inventory: alias %q: it must start with a letter and contain only letters, digits, '.', '_' and '-'", name)
+		}
+	}
+	return inv, nil
+}
+
+// importBuild finds the build of an inventory entry for this platform, in the
+// catalogue or, for builds that dropped out of it, from the exported URL
+func importBuild(reg *registry.Registry, entry inventory.Entry) (*registry.JavaVersion, error) {
+	targetOS, targetArch := registry.Platform()
+	samePlatform := entry.OS == targetOS && entry.Arch == targetArch
+
+	for _, b := range reg.Builds(entry.Version) {
+		if b.OS != targetOS || b.Arch != targetArch {
+			continue
+		}
+		if entry.Distribution != "" && !strings.EqualFold(entry.Distribution, b.Distribution) {
+			continue
+		}
+		if samePlatform && entry.Checksum != "" && !strings.EqualFold(entry.Checksum, b.Checksum) {
+			return nil, fmt.Errorf("the catalogue's build of Java %s does not match the exported checksum", entry.Version)
+		}
+		return &b, nil
+	}
+
+	if samePlatform && entry.DownloadURL != "" && entry.Checksum != "" {
+		major := entry.MajorVersion
+		if major == 0 {
+			major, _ = install.GetMajorVersion(entry.Version)
+		}
+		return &registry.JavaVersion{
+			Version:      entry.Version,
+			MajorVersion: major,
+			Distribution: entry.Distribution,
+			OS:           entry.OS,
+			Arch:         entry.Arch,
+			DownloadURL:  entry.DownloadURL,
+			Checksum:     entry.Checksum,
+			FileName:     entry.FileName,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w for %s", &registry.NotFoundError{Version: entry.Version}, project.PlatformKey(targetOS, targetArch))
+}
+
+// sameBuildPlatform reports whether an installed version and an inventory entry
+// were built for the same platform, so their checksums are comparable
+func sameBuildPlatform(meta *install.Metadata, entry inventory.Entry) bool {
+	return meta.OS == entry.OS && meta.Arch == entry.Arch
+}
+
+// restoreDefault makes the exported default version the default again, if it
+// is installed and not the default already
+func restoreDefault(ctx context.Context, cfg *config.Config, installer *install.Installer, def string, report *importReport) error {
+	if def == "" || !installer.IsInstalled(def) {
+		return nil
+	}
+	report.Default = def
+
+	current, _ := version.NewManager(cfg.InstallDir, cfg.StateFile).GetDefaultVersion()
+	switch {
+	case current == def:
+		return nil
+	case importDryRun:
+		fmt.Printf("Java %s would become the default\n", def)
+		return nil
+	}
+
+	if err := switchVersion(ctx, cfg, def); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Java %s is the default\n", def)
+	return nil
+}
+
+// restoreAliases adds the exported aliases, replacing existing ones of the same name
+func restoreAliases(ctx context.Context, cfg *config.Config, aliases map[string]string, report *importReport) error {
+	if len(aliases) == 0 {
+		return nil
+	}
+	report.Aliases = aliases
+
+	if importDryRun {
+		for _, name := range aliasNames(aliases) {
+			fmt.Printf("Alias %s would point to %s\n", name, aliases[name])
+		}
+		return nil
+	}
+
+	err := state.Update(ctx, cfg.StateFile, func(s *state.State) error {
+		if s.Aliases == nil {
+			s.Aliases = map[string]string{}
+		}
+		for name, target := range aliases {
+			s.Aliases[name] = target
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save aliases: %w", err)
+	}
+
+	for _, name := range aliasNames(aliases) {
+		fmt.Printf("✓ %s → %s\n", name, aliases[name])
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadInventoryAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases string
+		wantErr bool
+	}{
+		{name: "no aliases", aliases: `{}`},
+		{name: "valid aliases", aliases: `{"prod": "21.0.2+13", "legacy-8": "1.8.0"}`},
+		{name: "alias shadowing a major", aliases: `{"21": "17.0.10+7"}`, wantErr: true},
+		{name: "alias with a path", aliases: `{"../prod": "21.0.2+13"}`, wantErr: true},
+		{name: "empty alias name", aliases: `{"": "21.0.2+13"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "inventory.json")
+			content := `{"format": 1, "aliases": ` + tt.aliases + `, "versions": []}`
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := readInventory(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "alias") {
+				t.Errorf("readInventory() error = %v, want one naming the alias", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/project"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/rexqwer911/jvt/internal/version"
+	"golang.org/x/term"
+)
+
+// removalBlocker explains why an installed version should not be removed
+type removalBlocker struct {
+	reason string
+	// switchable is true when switching to another version resolves the blocker
+	switchable bool
+}
+
+// findRemovalBlockers checks whether a version is active, the recorded default,
+// pinned, the target of an alias, or required by a project file in the current
+// directory tree
+func findRemovalBlockers(cfg *config.Config, installer *install.Installer, v string) []removalBlocker {
+	var blockers []removalBlocker
+
+	mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+	if current, err := mgr.GetCurrentVersion(); err == nil && current == v {
+		blockers = append(blockers, removalBlocker{"it is the active version (JAVA_HOME)", true})
+	}
+	if def, err := mgr.GetDefaultVersion(); err == nil && def == v {
+		blockers = append(blockers, removalBlocker{"it is the default version", true})
+	}
+
+	if s, err := state.Load(cfg.StateFile); err == nil {
+		if slices.Contains(s.Pinned, v) {
+			blockers = append(blockers, removalBlocker{"it is pinned (see 'jvt unpin')", false})
+		}
+		for _, name := range aliasNames(s.Aliases) {
+			if matched, err := resolveInstalled(installer, s.Aliases[name]); err == nil && matched == v {
+				blockers = append(blockers, removalBlocker{fmt.Sprintf("alias %s points to it", name), false})
+			}
+		}
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		pins, _ := project.FindPins(cwd)
+		for _, pin := range pins {
+			if matched, err := resolveInstalled(installer, pin.Spec); err == nil && matched == v {
+				blockers = append(blockers, removalBlocker{fmt.Sprintf("it is required by %s", pin.Source), false})
+			}
+		}
+	}
+
+	return blockers
+}
+
+// describeBlockers joins blocker reasons into one sentence fragment
+func describeBlockers(blockers []removalBlocker) string {
+	reasons := make([]string, len(blockers))
+	for idx, b := range blockers {
+		reasons[idx] = b.reason
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// checkRemovalAllowed returns an error if a version must not be removed. Unless
+// force is set, the user is offered to switch to another installed build of
+// the same major version when that would make the removal safe.
+func checkRemovalAllowed(ctx context.Context, cfg *config.Config, installer *install.Installer, v string, force bool) error {
+	blockers := findRemovalBlockers(cfg, installer, v)
+	if len(blockers) == 0 || force {
+		return nil
+	}
+
+	// Offer a replacement of the same major version when switching is enough
+	allSwitchable := true
+	for _, b := range blockers {
+		allSwitchable = allSwitchable && b.switchable
+	}
+
+	if allSwitchable && isInteractive() {
+		if replacement := findReplacement(installer, v); replacement != "" {
+			prompt := fmt.Sprintf("Java %s is in use (%s). Switch to Java %s first?", v, describeBlockers(blockers), replacement)
+			if confirm(prompt) {
+				if err := switchVersion(ctx, cfg, replacement); err != nil {
+					return err
+				}
+				fmt.Printf("✓ Now using Java %s\n", replacement)
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("refusing to remove Java %s: %s (use --force to remove anyway)", v, describeBlockers(blockers))
+}
+
+// findReplacement returns the newest other installed version with the same major version
+func findReplacement(installer *install.Installer, v string) string {
+	major, err := install.GetMajorVersion(v)
+	if err != nil {
+		return ""
+	}
+
+	candidates, err := installer.GetInstalledByMajor(major)
+	if err != nil {
+		return ""
+	}
+
+	replacement := ""
+	for _, c := range candidates {
+		if c == v {
+			continue
+		}
+		if replacement == "" {
+			replacement = c
+			continue
+		}
+		if cmp, err := version.CompareVersions(c, replacement); err == nil && cmp > 0 {
+			replacement = c
+		}
+	}
+
+	return replacement
+}
+
+// isInteractive reports whether jvt can prompt the user
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info <version>",
+	Short: "Show details about an installed Java version",
+	Long:  "Display the distribution, download source, checksum and install date recorded for an installed Java version.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		versionStr := args[0]
+
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		installer := install.NewInstaller(cfg.InstallDir)
+
+		matchedVersion, err := resolveInstalled(installer, versionStr)
+		if err != nil {
+			return err
+		}
+
+		if structuredOutput() {
+			mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+			currentVersion, _ := mgr.GetCurrentVersion()
+			defaultVersion, _ := mgr.GetDefaultVersion()
+			return emit(newInstalledVersion(installer, matchedVersion, currentVersion, defaultVersion, pinnedVersions(cfg)))
+		}
+
+		fmt.Printf("Java %s\n", matchedVersion)
+		fmt.Printf("  Location:     %s\n", installer.GetJavaHome(matchedVersion))
+		if pinnedVersions(cfg)[matchedVersion] {
+			fmt.Println("  Pinned:       yes (kept by upgrade and uninstall)")
+		}
+
+		if release, err := installer.ReadRelease(matchedVersion); err == nil {
+			fmt.Printf("  Implementor:  %s\n", release.Implementor)
+			fmt.Printf("  Runtime:      %s\n", release.JavaRuntimeVersion)
+			fmt.Printf("  OS/Arch:      %s/%s\n", release.OSName, release.OSArch)
+			fmt.Printf("  Modules:      %d\n", len(release.Modules))
+		} else {
+			fmt.Printf("  Release file: unreadable (%v)\n", err)
+		}
+
+		meta, err := installer.ReadMetadata(matchedVersion)
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Println("  No install metadata recorded (installed by an older jvt).")
+				return nil
+			}
+			return err
+		}
+
+		fmt.Printf("  Distribution: %s\n", meta.Distribution)
+		fmt.Printf("  Platform:     %s/%s\n", meta.OS, meta.Arch)
+		fmt.Printf("  Source:       %s\n", meta.DownloadURL)
+		fmt.Printf("  Archive:      %s\n", meta.FileName)
+		fmt.Printf("  Checksum:     %s\n", meta.Checksum)
+		fmt.Printf("  Installed:    %s\n", meta.InstalledAt.Local().Format("2006-01-02 15:04:05"))
+
+		return nil
+	},
+}
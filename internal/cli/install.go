@@ -1,23 +1,53 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/rexqwer911/jvt/internal/config"
 	"github.com/rexqwer911/jvt/internal/download"
 	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/progress"
+	"github.com/rexqwer911/jvt/internal/project"
 	"github.com/rexqwer911/jvt/internal/registry"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// installWorkers is the number of versions downloaded concurrently when
+// several are installed or upgraded at once
+const installWorkers = 3
+
+var installLocked bool
+
 var installCmd = &cobra.Command{
 	Use:   "install <version>",
 	Short: "Install a specific Java version",
-	Long:  "Download and install a specific Java version.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		versionStr := args[0]
+	Long: `Download and install a specific Java version.
+
+With --locked, the exact build recorded in the project's jvt.lock is installed
+instead, and the install fails if the downloaded archive or an installed build
+doesn't match the lock.
 
+Examples:
+  jvt install 21          # Install the latest Java 21
+  jvt install 17.0.10+7   # Install an exact version
+  jvt install --locked    # Install the build recorded in jvt.lock`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if installLocked {
+			if len(args) > 0 {
+				return usageErrorf("--locked installs the version recorded in %s and takes no version", project.LockFileName)
+			}
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.GetConfig()
 		if err != nil {
 			return fmt.Errorf("failed to get config: %w", err)
@@ -27,15 +57,21 @@ var installCmd = &cobra.Command{
 			return fmt.Errorf("failed to create directories: %w", err)
 		}
 
+		installer := install.NewInstaller(cfg.InstallDir)
+
+		if installLocked {
+			return installLockedVersion(cmd, cfg, installer)
+		}
+
 		// Fetch available versions
 		fmt.Println("Fetching available versions...")
-		reg := registry.NewRegistry()
-		if err := reg.FetchAvailableVersions(); err != nil {
+		reg := newRegistry(cfg)
+		if err := reg.FetchAvailableVersions(cmd.Context()); err != nil {
 			return fmt.Errorf("failed to fetch versions: %w", err)
 		}
 
 		// Find the requested version
-		javaVersion, err := reg.FindVersion(versionStr)
+		javaVersion, err := reg.FindVersion(args[0])
 		if err != nil {
 			return fmt.Errorf("version not found: %w", err)
 		}
@@ -43,34 +79,216 @@ var installCmd = &cobra.Command{
 		fmt.Printf("\nFound: Java %s (%s)\n", javaVersion.Version, javaVersion.Distribution)
 
 		// Check if already installed
-		installer := install.NewInstaller(cfg.InstallDir)
 		if installer.IsInstalled(javaVersion.Version) {
 			fmt.Printf("Java %s is already installed.\n", javaVersion.Version)
-			return nil
+			return emitInstallResult(installer, javaVersion, "already-installed")
 		}
 
-		// Download
-		downloader := download.NewDownloader(cfg.CacheDir)
-		fmt.Printf("\nDownloading from: %s\n", javaVersion.DownloadURL)
+		return installVersion(cmd, cfg, installer, javaVersion)
+	},
+}
 
-		archivePath, err := downloader.DownloadAndVerify(
-			javaVersion.DownloadURL,
-			javaVersion.FileName,
-			javaVersion.Checksum,
-		)
-		if err != nil {
-			return fmt.Errorf("download failed: %w", err)
+func init() {
+	installCmd.Flags().BoolVar(&installLocked, "locked", false, "Install exactly the build recorded in jvt.lock")
+}
+
+// installLockedVersion installs the build the project's jvt.lock records for
+// this platform, refusing anything that has drifted from it
+func installLockedVersion(cmd *cobra.Command, cfg *config.Config, installer *install.Installer) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	lockPath, err := project.FindLock(cwd)
+	if err != nil {
+		return err
+	}
+	if lockPath == "" {
+		return fmt.Errorf("no %s found in %s or its parents; run 'jvt lock' first", project.LockFileName, cwd)
+	}
+
+	lock, err := project.ReadLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	// The lock must still describe what the project asks for
+	if spec := project.VersionSpec(filepath.Dir(lockPath)); spec != "" && spec != lock.Spec {
+		return fmt.Errorf("%s was resolved for %q but %s requires %q; run 'jvt lock' to re-resolve it",
+			lockPath, lock.Spec, project.VersionFileName, spec)
+	}
+
+	javaVersion, err := lockedVersion(lock)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Locked: Java %s (%s) from %s\n", javaVersion.Version, javaVersion.Distribution, lockPath)
+
+	if installer.IsInstalled(javaVersion.Version) {
+		meta, err := installer.ReadMetadata(javaVersion.Version)
+		if err != nil || !strings.EqualFold(meta.Checksum, javaVersion.Checksum) {
+			return fmt.Errorf("Java %s is installed from a different build than %s; uninstall it and run 'jvt install --locked' again",
+				javaVersion.Version, project.LockFileName)
 		}
+		fmt.Printf("Java %s is already installed.\n", javaVersion.Version)
+		return emitInstallResult(installer, javaVersion, "already-installed")
+	}
+
+	return installVersion(cmd, cfg, installer, javaVersion)
+}
+
+// installVersion downloads, verifies and installs a version
+func installVersion(cmd *cobra.Command, cfg *config.Config, installer *install.Installer, javaVersion *registry.JavaVersion) error {
+	// Download
+	downloader := newDownloader(cfg)
+	fmt.Printf("\nDownloading from: %s\n", javaVersion.DownloadURL)
 
-		// Install
-		fmt.Println("\nInstalling...")
-		if err := installer.Install(archivePath, javaVersion.Version); err != nil {
-			return fmt.Errorf("installation failed: %w", err)
+	archivePath, err := downloader.DownloadAndVerify(
+		cmd.Context(),
+		javaVersion.DownloadURL,
+		javaVersion.FileName,
+		javaVersion.Checksum,
+	)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	// Install
+	fmt.Println("\nInstalling...")
+	if err := installer.Install(cmd.Context(), archivePath, newInstallMetadata(javaVersion)); err != nil {
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	fmt.Printf("\n✓ Java %s installed successfully!\n", javaVersion.Version)
+	fmt.Printf("Run 'jvt use %d' to activate this version.\n", javaVersion.MajorVersion)
+
+	return emitInstallResult(installer, javaVersion, "installed")
+}
+
+// installConcurrently downloads and installs several versions and returns the
+// error of each, by index. Downloads run in parallel; the installs follow one
+// after another so their progress messages don't interleave.
+func installConcurrently(ctx context.Context, cfg *config.Config, installer *install.Installer, versions []*registry.JavaVersion) []error {
+	archives, errs := downloadConcurrently(ctx, cfg, versions)
+	for idx, v := range versions {
+		if errs[idx] != nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs[idx] = err
+			continue
 		}
+		errs[idx] = installArchive(ctx, installer, v, archives[idx])
+	}
+	return errs
+}
 
-		fmt.Printf("\n✓ Java %s installed successfully!\n", javaVersion.Version)
-		fmt.Printf("Run 'jvt use %d' to activate this version.\n", javaVersion.MajorVersion)
+// downloadConcurrently downloads and verifies several versions with a bounded
+// pool of workers, showing their progress together, and returns the archive
+// path or the error of each, by index
+func downloadConcurrently(ctx context.Context, cfg *config.Config, versions []*registry.JavaVersion) ([]string, []error) {
+	downloader := newDownloader(cfg)
+	archives := make([]string, len(versions))
+	errs := make([]error, len(versions))
+	if len(versions) == 0 {
+		return archives, errs
+	}
 
-		return nil
-	},
+	display := newProgressDisplay()
+	bars := make([]*progress.Bar, len(versions))
+	for idx, v := range versions {
+		bars[idx] = display.Add("Java "+v.Version, v.Size)
+	}
+	display.Start()
+	defer display.Stop()
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < installWorkers && w < len(versions); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				archives[idx], errs[idx] = downloadVerified(ctx, downloader, versions[idx], bars[idx])
+				bars[idx].Finish(errs[idx])
+			}
+		}()
+	}
+
+	for idx := range versions {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	return archives, errs
+}
+
+// downloadVerified downloads one version, reporting to bar, and verifies its checksum
+func downloadVerified(ctx context.Context, downloader *download.Downloader, v *registry.JavaVersion, bar *progress.Bar) (string, error) {
+	archivePath, err := downloader.DownloadWithProgress(ctx, v.DownloadURL, v.FileName, bar)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+
+	if v.Checksum != "" {
+		if err := downloader.VerifyChecksum(archivePath, v.Checksum); err != nil {
+			return "", fmt.Errorf("checksum verification failed: %w", err)
+		}
+	}
+	return archivePath, nil
+}
+
+// installArchive installs a downloaded version; a version that got installed
+// in the meantime counts as success
+func installArchive(ctx context.Context, installer *install.Installer, v *registry.JavaVersion, archivePath string) error {
+	if err := installer.Install(ctx, archivePath, newInstallMetadata(v)); err != nil {
+		var alreadyInstalled *install.AlreadyInstalledError
+		if errors.As(err, &alreadyInstalled) {
+			return nil
+		}
+		return fmt.Errorf("installation failed: %w", err)
+	}
+	return nil
+}
+
+// newProgressDisplay shows download progress as live bars on a terminal, and
+// as plain messages otherwise
+func newProgressDisplay() *progress.Multi {
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		return progress.NewMulti(os.Stderr, true)
+	}
+	return progress.NewMulti(os.Stdout, false)
+}
+
+// installResult is the structured output of install
+type installResult struct {
+	Version  string            `json:"version"`
+	Status   string            `json:"status"`
+	Metadata *install.Metadata `json:"metadata,omitempty"`
+}
+
+// emitInstallResult emits the outcome of an install with the recorded metadata
+func emitInstallResult(installer *install.Installer, v *registry.JavaVersion, status string) error {
+	result := installResult{Version: v.Version, Status: status}
+	if meta, err := installer.ReadMetadata(v.Version); err == nil {
+		result.Metadata = meta
+	}
+	return emit(result)
+}
+
+// newInstallMetadata builds the install manifest recorded for a registry version
+func newInstallMetadata(v *registry.JavaVersion) *install.Metadata {
+	return &install.Metadata{
+		Version:      v.Version,
+		MajorVersion: v.MajorVersion,
+		Distribution: v.Distribution,
+		OS:           v.OS,
+		Arch:         v.Arch,
+		DownloadURL:  v.DownloadURL,
+		Checksum:     v.Checksum,
+		FileName:     v.FileName,
+	}
 }
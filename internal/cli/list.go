@@ -10,6 +10,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var listLong bool
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Short:   "List installed Java versions",
@@ -27,43 +29,135 @@ var listCmd = &cobra.Command{
 			return fmt.Errorf("failed to list installed versions: %w", err)
 		}
 
+		// Get current version for checking
+		mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+		currentVersion, _ := mgr.GetCurrentVersion() // Ignore error (might not be set)
+
+		pinned := pinnedVersions(cfg)
+
+		if structuredOutput() {
+			defaultVersion, _ := mgr.GetDefaultVersion()
+			list := installedList{Versions: []installedVersion{}}
+			for _, v := range versions {
+				list.Versions = append(list.Versions, newInstalledVersion(installer, v, currentVersion, defaultVersion, pinned))
+			}
+			return emit(list)
+		}
+
 		if len(versions) == 0 {
 			fmt.Println("No Java versions installed.")
 			fmt.Println("Use 'jvt install <version>' to install a version.")
 			return nil
 		}
 
-		// Get current version for checking
-		mgr := version.NewManager(cfg.InstallDir)
-		currentVersion, _ := mgr.GetCurrentVersion() // Ignore error (might not be set)
-
 		fmt.Println("Installed Java versions:")
 		for _, v := range versions {
-			if v == currentVersion {
+			switch {
+			case v == currentVersion && pinned[v]:
+				fmt.Printf("  * %s (current, pinned)\n", v)
+			case v == currentVersion:
 				fmt.Printf("  * %s (current)\n", v)
-			} else {
+			case pinned[v]:
+				fmt.Printf("    %s (pinned)\n", v)
+			default:
 				fmt.Printf("    %s\n", v)
 			}
+
+			if listLong {
+				printInstallDetails(installer, v)
+			}
 		}
 
 		return nil
 	},
 }
 
+func init() {
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "Show distribution and release details")
+}
+
+// installedVersion is the structured form of an installed Java version
+type installedVersion struct {
+	Version  string               `json:"version"`
+	Current  bool                 `json:"current"`
+	Default  bool                 `json:"default"`
+	Pinned   bool                 `json:"pinned"`
+	JavaHome string               `json:"java_home"`
+	Metadata *install.Metadata    `json:"metadata,omitempty"`
+	Release  *install.ReleaseInfo `json:"release,omitempty"`
+}
+
+// installedList is the structured output of list
+type installedList struct {
+	Versions []installedVersion `json:"versions"`
+}
+
+// newInstalledVersion collects the recorded details of an installed version
+func newInstalledVersion(installer *install.Installer, v, currentVersion, defaultVersion string, pinned map[string]bool) installedVersion {
+	iv := installedVersion{
+		Version:  v,
+		Current:  v == currentVersion,
+		Default:  v == defaultVersion,
+		Pinned:   pinned[v],
+		JavaHome: installer.GetJavaHome(v),
+	}
+	if meta, err := installer.ReadMetadata(v); err == nil {
+		iv.Metadata = meta
+	}
+	if release, err := installer.ReadRelease(v); err == nil {
+		iv.Release = release
+	}
+	return iv
+}
+
+// printInstallDetails prints the metadata and release fields of an installed version
+func printInstallDetails(installer *install.Installer, v string) {
+	if meta, err := installer.ReadMetadata(v); err == nil {
+		fmt.Printf("        Distribution:    %s\n", meta.Distribution)
+	}
+
+	release, err := installer.ReadRelease(v)
+	if err != nil {
+		fmt.Printf("        Release file:    unreadable (%v)\n", err)
+		return
+	}
+
+	fmt.Printf("        Implementor:     %s\n", release.Implementor)
+	fmt.Printf("        Runtime version: %s\n", release.JavaRuntimeVersion)
+	fmt.Printf("        Arch:            %s\n", release.OSArch)
+}
+
+// remoteList is the structured output of list-remote
+type remoteList struct {
+	Versions []registry.JavaVersion `json:"versions"`
+}
+
 var listRemoteCmd = &cobra.Command{
 	Use:     "list-remote",
 	Short:   "List available Java versions for download",
 	Long:    "Display all Java versions available for download from configured sources.",
 	Aliases: []string{"ls-remote"},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
 		fmt.Println("Fetching available Java versions from Adoptium...")
 
-		reg := registry.NewRegistry()
-		if err := reg.FetchAvailableVersions(); err != nil {
+		reg := newRegistry(cfg)
+		if err := reg.FetchAvailableVersions(cmd.Context()); err != nil {
 			return fmt.Errorf("failed to fetch versions: %w", err)
 		}
 
 		versions := reg.GetVersions()
+		if structuredOutput() {
+			if versions == nil {
+				versions = []registry.JavaVersion{}
+			}
+			return emit(remoteList{Versions: versions})
+		}
+
 		if len(versions) == 0 {
 			fmt.Println("No versions found.")
 			return nil
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/project"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var lockUpdate bool
+
+var lockCmd = &cobra.Command{
+	Use:   "lock [version]",
+	Short: "Record the exact JDK build a project uses in jvt.lock",
+	Long: `Resolve a version spec and record the distribution, full version, and the
+download URL and SHA-256 of every platform in jvt.lock, so that
+'jvt install --locked' installs identical bytes on every machine.
+
+Without a version, the spec of the project's .java-version file is used, or
+that of the existing jvt.lock. A new lock file is written next to the nearest
+.java-version file, or to the current directory.
+
+Examples:
+  jvt lock            # Lock the version required by .java-version
+  jvt lock 21         # Lock the latest Java 21
+  jvt lock --update   # Re-resolve the locked spec, e.g. after a security release`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		lockPath, existing, err := findLockTarget()
+		if err != nil {
+			return err
+		}
+
+		spec, err := lockSpec(args, lockPath, existing)
+		if err != nil {
+			return err
+		}
+
+		result := lockResult{Path: lockPath, Spec: spec}
+
+		// An existing lock is only re-resolved when asked to, or for a new spec
+		if existing != nil && !lockUpdate && spec == existing.Spec {
+			fmt.Printf("%s locks Java %s (%s).\n", lockPath, existing.Version, existing.Spec)
+			fmt.Println("Run 'jvt lock --update' to re-resolve it.")
+			result.Version = existing.Version
+			result.Platforms = lockPlatforms(existing)
+			result.Status = "unchanged"
+			return emit(result)
+		}
+
+		// Re-resolving must see the current catalogue, not a cached copy
+		if lockUpdate {
+			refreshCache = true
+		}
+
+		fmt.Println("Fetching available versions...")
+		reg := newRegistry(cfg)
+		if err := reg.FetchAvailableVersions(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to fetch versions: %w", err)
+		}
+
+		javaVersion, err := reg.FindVersion(expandAlias(spec))
+		if err != nil {
+			return fmt.Errorf("version not found: %w", err)
+		}
+
+		lock := newLock(spec, javaVersion, reg.Builds(javaVersion.Version))
+		result.Version = lock.Version
+		result.Platforms = lockPlatforms(lock)
+
+		if existing != nil && reflect.DeepEqual(existing, lock) {
+			fmt.Printf("%s is up to date (Java %s).\n", lockPath, lock.Version)
+			result.Status = "unchanged"
+			return emit(result)
+		}
+
+		if err := project.WriteLock(lockPath, lock); err != nil {
+			return fmt.Errorf("failed to write %s: %w", lockPath, err)
+		}
+
+		if existing != nil && existing.Version != lock.Version {
+			fmt.Printf("✓ Updated %s: Java %s → %s\n", lockPath, existing.Version, lock.Version)
+			result.Previous = existing.Version
+			result.Status = "updated"
+		} else {
+			fmt.Printf("✓ Locked Java %s (%s) for %d platform(s) in %s\n", lock.Version, lock.Distribution, len(lock.Platforms), lockPath)
+			result.Status = "locked"
+		}
+
+		return emit(result)
+	},
+}
+
+// lockResult is the structured output of lock
+type lockResult struct {
+	Path      string   `json:"path"`
+	Spec      string   `json:"spec"`
+	Version   string   `json:"version"`
+	Previous  string   `json:"previous,omitempty"`
+	Platforms []string `json:"platforms"`
+	Status    string   `json:"status"`
+}
+
+func init() {
+	lockCmd.Flags().BoolVar(&lockUpdate, "update", false, "Re-resolve the locked version spec against the current catalogue")
+}
+
+// findLockTarget returns the lock file of the current project and its contents,
+// or where a new lock file belongs if there is none yet
+func findLockTarget() (string, *project.Lock, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+
+	lockPath, err := project.FindLock(cwd)
+	if err != nil {
+		return "", nil, err
+	}
+	if lockPath != "" {
+		lock, err := project.ReadLock(lockPath)
+		if err != nil {
+			return "", nil, err
+		}
+		return lockPath, lock, nil
+	}
+
+	dir := cwd
+	if pins, _ := project.FindPins(cwd); len(pins) > 0 {
+		dir = filepath.Dir(pins[0].Source)
+	}
+	return filepath.Join(dir, project.LockFileName), nil, nil
+}
+
+// lockSpec returns the version spec to lock: the argument, the one required
+// by the .java-version file next to the lock, or the spec of the existing lock
+func lockSpec(args []string, lockPath string, existing *project.Lock) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if spec := project.VersionSpec(filepath.Dir(lockPath)); spec != "" {
+		return spec, nil
+	}
+	if existing != nil {
+		return existing.Spec, nil
+	}
+	return "", usageErrorf("no version given and no %s found; run 'jvt lock <version>'", project.VersionFileName)
+}
+
+// newLock records the builds of a resolved version for every platform
+func newLock(spec string, v *registry.JavaVersion, builds []registry.JavaVersion) *project.Lock {
+	lock := &project.Lock{
+		Spec:         spec,
+		Distribution: v.Distribution,
+		Version:      v.Version,
+		MajorVersion: v.MajorVersion,
+		Platforms:    map[string]project.LockedBuild{},
+	}
+
+	for _, b := range builds {
+		key := project.PlatformKey(b.OS, b.Arch)
+		// A build without a checksum can't be locked
+		if _, seen := lock.Platforms[key]; seen || b.Checksum == "" {
+			continue
+		}
+		lock.Platforms[key] = project.LockedBuild{
+			FileName: b.FileName,
+			URL:      b.DownloadURL,
+			SHA256:   b.Checksum,
+		}
+	}
+
+	return lock
+}
+
+// lockPlatforms returns the platforms of a lock in a stable order
+func lockPlatforms(lock *project.Lock) []string {
+	platforms := make([]string, 0, len(lock.Platforms))
+	for key := range lock.Platforms {
+		platforms = append(platforms, key)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// lockedVersion returns the locked build for the current platform
+func lockedVersion(lock *project.Lock) (*registry.JavaVersion, error) {
+	targetOS, targetArch := registry.Platform()
+	key := project.PlatformKey(targetOS, targetArch)
+
+	build, ok := lock.Platforms[key]
+	if !ok {
+		return nil, fmt.Errorf("%s has no build of Java %s for %s", project.LockFileName, lock.Version, key)
+	}
+
+	return &registry.JavaVersion{
+		Version:      lock.Version,
+		MajorVersion: lock.MajorVersion,
+		Distribution: lock.Distribution,
+		OS:           targetOS,
+		Arch:         targetArch,
+		DownloadURL:  build.URL,
+		Checksum:     build.SHA256,
+		FileName:     build.FileName,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormat string
+
+// emitted records that a result document was written, so a later error is
+// reported on stderr instead of as a second document
+var emitted bool
+
+// stdout is the real standard output. In structured output modes os.Stdout is
+// pointed at stderr so that progress messages never mix with the document.
+var stdout = os.Stdout
+
+// setupOutput validates --output and redirects human-readable messages to
+// stderr when a structured format is selected
+func setupOutput() error {
+	switch outputFormat {
+	case outputTable:
+		return nil
+	case outputJSON, outputYAML:
+		os.Stdout = os.Stderr
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %q (expected json, yaml or table)", outputFormat)
+	}
+}
+
+// structuredOutput reports whether a machine-readable format was requested
+func structuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// emit writes a command result in the selected structured format. It is a no-op
+// in table mode, where commands print their own prose.
+func emit(v any) error {
+	if !structuredOutput() {
+		return nil
+	}
+	emitted = true
+	return encode(stdout, v)
+}
+
+// encode writes v as JSON or YAML. YAML is produced from the JSON encoding so
+// both formats share one schema, defined by the json struct tags.
+func encode(w io.Writer, v any) error {
+	var data bytes.Buffer
+	jsonEnc := json.NewEncoder(&data)
+	jsonEnc.SetEscapeHTML(false)
+	jsonEnc.SetIndent("", "  ")
+	if err := jsonEnc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	if outputFormat != outputYAML {
+		_, err := w.Write(data.Bytes())
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data.Bytes(), &node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	clearStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// clearStyle drops the flow and quoting styles inherited from the JSON input
+// so the YAML encoder picks its usual block style
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// actionResult is the structured output of commands that change a single version
+type actionResult struct {
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+// errorDocument is the structured form of a failed command
+type errorDocument struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind"`
+	Code    int    `json:"code"`
+}
+
+// PrintError reports a command error, as a structured document on stdout when
+// --output is json or yaml, and as plain text on stderr otherwise
+func PrintError(err error) {
+	var commandExitErr *commandExitError
+	if errors.As(err, &commandExitErr) {
+		return
+	}
+
+	if structuredOutput() && !emitted {
+		code, kind := classifyError(err)
+		doc := errorDocument{Error: errorDetail{Message: err.Error(), Kind: kind, Code: code}}
+		if encErr := encode(stdout, doc); encErr == nil {
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var pinCmd = &cobra.Command{
+	Use:   "pin [version]",
+	Short: "Protect an installed build from upgrade and removal",
+	Long: `Pin an installed build so that upgrade never replaces or removes it, and
+uninstall refuses to remove it without --force. Without a version, the pinned
+builds are listed.
+
+Examples:
+  jvt pin 17.0.6+10    # Keep this build for a vendor-certified app server
+  jvt pin              # List pinned builds
+  jvt unpin 17.0.6+10  # Let upgrade manage it again`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		if len(args) == 0 {
+			return listPinned(cfg)
+		}
+
+		// A pin protects one build, so the spec must name exactly one
+		installer := install.NewInstaller(cfg.InstallDir)
+		matchedVersion, err := resolveInstalledUnique(installer, args[0])
+		if err != nil {
+			return err
+		}
+
+		alreadyPinned := false
+		err = state.Update(cmd.Context(), cfg.StateFile, func(s *state.State) error {
+			if slices.Contains(s.Pinned, matchedVersion) {
+				alreadyPinned = true
+				return nil
+			}
+			s.Pinned = append(s.Pinned, matchedVersion)
+			slices.Sort(s.Pinned)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save pin: %w", err)
+		}
+
+		if alreadyPinned {
+			fmt.Printf("Java %s is already pinned.\n", matchedVersion)
+		} else {
+			fmt.Printf("✓ Pinned Java %s\n", matchedVersion)
+		}
+		return emit(actionResult{Version: matchedVersion, Status: "pinned"})
+	},
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <version>",
+	Short: "Remove the pin from a build",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		// A pin may outlive its build after a forced uninstall, so exact
+		// versions are looked up among the pins before the installed versions
+		target := expandAlias(args[0])
+		s, err := state.Load(cfg.StateFile)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(s.Pinned, target) {
+			target, err = resolveInstalledUnique(install.NewInstaller(cfg.InstallDir), target)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = state.Update(cmd.Context(), cfg.StateFile, func(s *state.State) error {
+			idx := slices.Index(s.Pinned, target)
+			if idx < 0 {
+				return fmt.Errorf("Java %s is not pinned", target)
+			}
+			s.Pinned = slices.Delete(s.Pinned, idx, idx+1)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("✓ Unpinned Java %s\n", target)
+		return emit(actionResult{Version: target, Status: "unpinned"})
+	},
+}
+
+// pinnedList is the structured output of pin without arguments
+type pinnedList struct {
+	Pinned []string `json:"pinned"`
+}
+
+// listPinned prints the pinned builds
+func listPinned(cfg *config.Config) error {
+	s, err := state.Load(cfg.StateFile)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		list := pinnedList{Pinned: []string{}}
+		list.Pinned = append(list.Pinned, s.Pinned...)
+		return emit(list)
+	}
+
+	if len(s.Pinned) == 0 {
+		fmt.Println("No pinned versions.")
+		fmt.Println("Use 'jvt pin <version>' to pin one.")
+		return nil
+	}
+
+	fmt.Println("Pinned Java versions:")
+	for _, v := range s.Pinned {
+		fmt.Printf("    %s\n", v)
+	}
+	return nil
+}
+
+// pinnedVersions returns the set of pinned builds
+func pinnedVersions(cfg *config.Config) map[string]bool {
+	pinned := map[string]bool{}
+	if s, err := state.Load(cfg.StateFile); err == nil {
+		for _, v := range s.Pinned {
+			pinned[v] = true
+		}
+	}
+	return pinned
+}
+
+// unpinnedVersions returns the versions that are not pinned, in their order
+func unpinnedVersions(cfg *config.Config, versions []string) []string {
+	pinned := pinnedVersions(cfg)
+	var unpinned []string
+	for _, v := range versions {
+		if !pinned[v] {
+			unpinned = append(unpinned, v)
+		}
+	}
+	return unpinned
+}
+
+// dropPin forgets the pin of a build that no longer exists
+func dropPin(ctx context.Context, cfg *config.Config, v string) error {
+	return state.Update(ctx, cfg.StateFile, func(s *state.State) error {
+		if idx := slices.Index(s.Pinned, v); idx >= 0 {
+			s.Pinned = slices.Delete(s.Pinned, idx, idx+1)
+		}
+		return nil
+	})
+}
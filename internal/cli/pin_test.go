@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/state"
+)
+
+// newTestInstall creates an install directory holding versions and a state
+// file holding s, and returns a config pointing at them. JAVA_HOME and the
+// working directory are isolated from the machine running the tests.
+func newTestInstall(t *testing.T, versions []string, s state.State) (*config.Config, *install.Installer) {
+	t.Helper()
+
+	root := t.TempDir()
+	cfg := &config.Config{
+		RootDir:    root,
+		InstallDir: filepath.Join(root, "versions"),
+		StateFile:  filepath.Join(root, "state.json"),
+	}
+	for _, v := range versions {
+		if err := os.MkdirAll(filepath.Join(cfg.InstallDir, v, "bin"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := state.Update(context.Background(), cfg.StateFile, func(st *state.State) error {
+		*st = s
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("JAVA_HOME", "")
+	t.Chdir(t.TempDir())
+	return cfg, install.NewInstaller(cfg.InstallDir)
+}
+
+func TestUnpinnedVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		pinned   []string
+		want     []string
+	}{
+		{name: "nothing pinned", versions: []string{"17.0.6+10", "17.0.9+9"}, want: []string{"17.0.6+10", "17.0.9+9"}},
+		{name: "one build pinned", versions: []string{"17.0.6+10", "17.0.9+9"}, pinned: []string{"17.0.6+10"}, want: []string{"17.0.9+9"}},
+		{name: "every build pinned", versions: []string{"17.0.6+10", "17.0.9+9"}, pinned: []string{"17.0.6+10", "17.0.9+9"}, want: nil},
+		{name: "pin of another major", versions: []string{"17.0.9+9"}, pinned: []string{"21.0.2+13"}, want: []string{"17.0.9+9"}},
+		{name: "pin is not a prefix match", versions: []string{"17.0.6+10", "17.0.6+10-custom"}, pinned: []string{"17.0.6+10"}, want: []string{"17.0.6+10-custom"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := newTestInstall(t, tt.versions, state.State{Pinned: tt.pinned})
+			if got := unpinnedVersions(cfg, tt.versions); !slices.Equal(got, tt.want) {
+				t.Errorf("unpinnedVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanUpgradeSkipsPinnedMajors(t *testing.T) {
+	tests := []struct {
+		name          string
+		versions      []string
+		pinned        []string
+		wantStatus    string
+		wantInstalled string
+	}{
+		{name: "only build pinned", versions: []string{"17.0.6+10"}, pinned: []string{"17.0.6+10"}, wantStatus: "pinned", wantInstalled: "17.0.6+10"},
+		{name: "every build pinned", versions: []string{"17.0.6+10", "17.0.9+9"}, pinned: []string{"17.0.6+10", "17.0.9+9"}, wantStatus: "pinned", wantInstalled: "17.0.6+10"},
+		{name: "only another major installed", versions: []string{"21.0.2+13"}, pinned: []string{"21.0.2+13"}, wantStatus: "not-installed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, installer := newTestInstall(t, tt.versions, state.State{Pinned: tt.pinned})
+
+			// Neither outcome consults the catalogue, so there is no registry
+			decision, plan, err := planUpgrade(context.Background(), cfg, installer, nil, &config.UpgradePolicy{}, 17)
+			if err != nil {
+				t.Fatalf("planUpgrade() error = %v", err)
+			}
+			if plan != nil {
+				t.Errorf("planUpgrade() planned an upgrade to %s", plan.target.Version)
+			}
+			if decision.Status != tt.wantStatus || decision.Installed != tt.wantInstalled {
+				t.Errorf("decision = %s (%q), want %s (%q)", decision.Status, decision.Installed, tt.wantStatus, tt.wantInstalled)
+			}
+		})
+	}
+}
+
+func TestCheckRemovalAllowedPinned(t *testing.T) {
+	tests := []struct {
+		name    string
+		pinned  []string
+		remove  string
+		force   bool
+		wantErr bool
+	}{
+		{name: "pinned build is protected", pinned: []string{"17.0.6+10"}, remove: "17.0.6+10", wantErr: true},
+		{name: "force removes a pinned build", pinned: []string{"17.0.6+10"}, remove: "17.0.6+10", force: true},
+		{name: "other builds of the major are not protected", pinned: []string{"17.0.6+10"}, remove: "17.0.9+9"},
+		{name: "nothing pinned", remove: "17.0.6+10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, installer := newTestInstall(t, []string{"17.0.6+10", "17.0.9+9"}, state.State{Pinned: tt.pinned})
+
+			err := checkRemovalAllowed(context.Background(), cfg, installer, tt.remove, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkRemovalAllowed() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "pinned") {
+				t.Errorf("checkRemovalAllowed() error = %v, want one naming the pin", err)
+			}
+
+			// Switching to the new build never releases a pin after an upgrade
+			blocked := upgradeRemovalBlocker(cfg, installer, tt.remove, true) != ""
+			if blocked != slices.Contains(tt.pinned, tt.remove) {
+				t.Errorf("upgradeRemovalBlocker() blocked = %v, want %v", blocked, !blocked)
+			}
+		})
+	}
+}
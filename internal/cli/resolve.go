@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// newInstalledResolver builds a version resolver over the installed versions
+func newInstalledResolver(installer *install.Installer, spec string) (*version.Resolver, error) {
+	versions, err := installer.ListInstalled()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w. Use 'jvt install %s' first", &version.NotInstalledError{Spec: spec}, spec)
+	}
+
+	return version.NewResolver(versions), nil
+}
+
+// expandAlias returns the version spec an alias stands for, or spec unchanged
+// if it is not an alias
+func expandAlias(spec string) string {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return spec
+	}
+
+	s, err := state.Load(cfg.StateFile)
+	if err != nil {
+		return spec
+	}
+
+	if target, ok := s.Aliases[spec]; ok {
+		return target
+	}
+	return spec
+}
+
+// resolveInstalled resolves a version spec or alias to the newest matching
+// installed version
+func resolveInstalled(installer *install.Installer, spec string) (string, error) {
+	spec = expandAlias(spec)
+	resolver, err := newInstalledResolver(installer, spec)
+	if err != nil {
+		return "", err
+	}
+	return resolver.Resolve(spec)
+}
+
+// resolveInstalledUnique resolves a version spec or alias that must match
+// exactly one installed version, for operations that remove or replace files
+func resolveInstalledUnique(installer *install.Installer, spec string) (string, error) {
+	spec = expandAlias(spec)
+	resolver, err := newInstalledResolver(installer, spec)
+	if err != nil {
+		return "", err
+	}
+	return resolver.ResolveUnique(spec)
+}
@@ -1,6 +1,13 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
 	"github.com/spf13/cobra"
 )
 
@@ -11,21 +18,84 @@ var rootCmd = &cobra.Command{
 download, install, and switch between different Java versions easily.
 
 Similar to nvm for Node.js, jvt simplifies Java version management.`,
-	Version: "1.3.0",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if isCompletionRequest(cmd) {
+			setupCompletion(cmd)
+			return nil
+		}
+
+		if err := setupOutput(); err != nil {
+			return err
+		}
+
+		// The command line is valid; later failures are not usage problems
+		commandStarted = true
+		cmd.SilenceUsage = true
+
+		cleanupStaging()
+		cleanupSelfUpdate()
+		return nil
+	},
+}
+
+// SetVersion sets the version reported by --version and compared by self-update
+func SetVersion(version string) {
+	rootCmd.Version = version
 }
 
 // Execute runs the root command
 func Execute() error {
-	rootCmd.CompletionOptions.DisableDefaultCmd = true
-	return rootCmd.Execute()
+	// Errors are reported by PrintError, in the selected output format
+	rootCmd.SilenceErrors = true
+
+	// Ctrl-C cancels network operations through the command context; a second
+	// Ctrl-C falls back to the default behaviour and terminates immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Use only cached version data and downloads; never access the network")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "Output format: table, json or yaml")
+	rootCmd.RegisterFlagCompletionFunc("output", completeOutputFormat)
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "Ignore the cache age and revalidate version data with the remote registry")
+
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(listRemoteCmd)
 	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(aliasCmd)
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
 	rootCmd.AddCommand(useCmd)
 	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(currentCmd)
 	rootCmd.AddCommand(upgradeCmd)
+	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(repairCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+// cleanupStaging removes directories left behind by interrupted installs
+func cleanupStaging() {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return
+	}
+
+	if err := install.NewInstaller(cfg.InstallDir).CleanupStaging(); err != nil {
+		fmt.Printf("Warning: failed to clean up interrupted installs: %v\n", err)
+	}
 }
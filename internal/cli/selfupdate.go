@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/download"
+	"github.com/rexqwer911/jvt/internal/selfupdate"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selfUpdateCheck   bool
+	selfUpdateChannel string
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update jvt to the latest release",
+	Long: `Download the latest jvt release for this platform from GitHub, verify its
+checksum and replace the running binary.
+
+The release channel given with --channel is remembered for later updates.
+
+Examples:
+  jvt self-update                       # Update to the latest stable release
+  jvt self-update --check               # Only report whether an update is available
+  jvt self-update --channel prerelease  # Follow pre-releases from now on`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		if offlineMode {
+			return &download.NetworkError{URL: cfg.UpdateURL, Err: fmt.Errorf("self-update needs network access and --offline is set")}
+		}
+
+		// A development build can't be compared with releases, and replacing
+		// it would silently discard the local changes
+		current := cmd.Root().Version
+		if !selfupdate.IsReleaseVersion(current) {
+			return fmt.Errorf("jvt %s is not a release build and can't be updated; install a release instead", current)
+		}
+
+		channel, err := updateChannel(cmd, cfg)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Checking for jvt updates (%s channel)...\n", channel)
+		updater := selfupdate.NewUpdater(cfg.UpdateURL)
+		release, err := updater.Latest(cmd.Context(), channel)
+		if err != nil {
+			return fmt.Errorf("failed to check for updates: %w", err)
+		}
+
+		result := selfUpdateResult{Current: current, Latest: release.Version, Channel: channel}
+
+		if !selfupdate.IsNewer(release.Version, current) {
+			fmt.Printf("jvt %s is up to date.\n", current)
+			result.Status = "up-to-date"
+			return emit(result)
+		}
+
+		if selfUpdateCheck {
+			fmt.Printf("jvt %s is available (current: %s).\n", release.Version, current)
+			fmt.Println("Run 'jvt self-update' to install it.")
+			result.Status = "available"
+			return emit(result)
+		}
+
+		exePath, err := currentExecutable()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Updating jvt %s → %s...\n", current, release.Version)
+		if err := updater.Apply(cmd.Context(), release, exePath); err != nil {
+			return fmt.Errorf("self-update failed: %w", err)
+		}
+
+		fmt.Printf("✓ jvt updated to %s\n", release.Version)
+		result.Status = "updated"
+		return emit(result)
+	},
+}
+
+// selfUpdateResult is the structured output of self-update
+type selfUpdateResult struct {
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only check whether an update is available")
+	selfUpdateCmd.Flags().StringVar(&selfUpdateChannel, "channel", "", "Release channel to follow and remember: stable or prerelease")
+	selfUpdateCmd.RegisterFlagCompletionFunc("channel", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{selfupdate.ChannelStable, selfupdate.ChannelPrerelease}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// updateChannel returns the channel given with --channel, pinning it for later
+// runs, or the previously pinned channel
+func updateChannel(cmd *cobra.Command, cfg *config.Config) (string, error) {
+	if cmd.Flags().Changed("channel") {
+		if !selfupdate.ValidChannel(selfUpdateChannel) {
+			return "", usageErrorf("invalid channel %q (expected %s or %s)", selfUpdateChannel, selfupdate.ChannelStable, selfupdate.ChannelPrerelease)
+		}
+
+		err := state.Update(cmd.Context(), cfg.StateFile, func(s *state.State) error {
+			s.UpdateChannel = selfUpdateChannel
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to pin update channel: %w", err)
+		}
+		return selfUpdateChannel, nil
+	}
+
+	s, err := state.Load(cfg.StateFile)
+	if err != nil {
+		return "", err
+	}
+	if s.UpdateChannel == "" {
+		return selfupdate.ChannelStable, nil
+	}
+	return s.UpdateChannel, nil
+}
+
+// currentExecutable returns the path of the running jvt binary, with symlinks resolved
+func currentExecutable() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the jvt executable: %w", err)
+	}
+	return filepath.EvalSymlinks(exePath)
+}
+
+// cleanupSelfUpdate removes the binary replaced by a previous self-update
+func cleanupSelfUpdate() {
+	if exePath, err := currentExecutable(); err == nil {
+		selfupdate.CleanupOld(exePath)
+	}
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/project"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncFile   string
+	syncPrune  bool
+	syncDryRun bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install every JDK the project's jvt.toml requires",
+	Long: `Install the JDKs listed in the project's jvt.toml that are missing, downloading
+them in parallel, and report the status of each.
+
+jvt.toml lists one [[jdk]] table per JDK:
+
+  [[jdk]]
+  name = "main build"
+  version = "17"
+
+  [[jdk]]
+  name = "legacy module"
+  version = "8"
+
+  [[jdk]]
+  name = "native tests"
+  version = "21"
+  distribution = "temurin"
+
+With --prune, installed versions the manifest doesn't list are removed, except
+the active and default versions and ones required by another project file.
+
+Examples:
+  jvt sync                  # Install what jvt.toml requires
+  jvt sync --dry-run        # Only report what is missing
+  jvt sync --prune          # Also remove versions that are not listed
+  jvt sync -f ~/team.toml   # Use a manifest from elsewhere`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		if err := cfg.EnsureDirectories(); err != nil {
+			return fmt.Errorf("failed to create directories: %w", err)
+		}
+
+		manifestPath, err := findManifest()
+		if err != nil {
+			return err
+		}
+
+		manifest, err := project.ReadManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Syncing %d JDK(s) from %s\n", len(manifest.JDKs), manifestPath)
+
+		installer := install.NewInstaller(cfg.InstallDir)
+		report := syncReport{Manifest: manifestPath}
+		for _, jdk := range manifest.JDKs {
+			result := syncResult{Name: jdk.Name, Spec: jdk.Version, Distribution: jdk.Distribution}
+			if v := installedMatch(installer, jdk); v != "" {
+				result.Version = v
+				result.Status = "ok"
+			}
+			report.JDKs = append(report.JDKs, result)
+		}
+
+		if err := syncMissing(cmd.Context(), cfg, installer, manifest, report.JDKs); err != nil {
+			return err
+		}
+
+		var firstErr error
+		failed := 0
+		for idx, r := range report.JDKs {
+			label := syncLabel(manifest.JDKs[idx])
+			switch r.Status {
+			case "ok":
+				fmt.Printf("✓ %s: Java %s (already installed)\n", label, r.Version)
+			case "installed":
+				fmt.Printf("✓ %s: Java %s installed\n", label, r.Version)
+			case "missing":
+				fmt.Printf("+ %s: Java %s would be installed\n", label, r.Version)
+			default:
+				fmt.Printf("✗ %s: %s\n", label, r.Error)
+				failed++
+				if firstErr == nil {
+					firstErr = r.err
+				}
+			}
+		}
+
+		if syncPrune {
+			if failed > 0 {
+				fmt.Println("\nSkipping --prune because not every JDK could be synced.")
+			} else {
+				pruned, err := pruneUnlisted(cmd.Context(), cfg, installer, report.JDKs)
+				if err != nil {
+					return err
+				}
+				report.Pruned = pruned
+			}
+		}
+
+		if err := emit(report); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d JDK(s) could not be synced: %w", failed, len(report.JDKs), firstErr)
+		}
+
+		return nil
+	},
+}
+
+// syncReport is the structured output of sync
+type syncReport struct {
+	Manifest string        `json:"manifest"`
+	JDKs     []syncResult  `json:"jdks"`
+	Pruned   []pruneResult `json:"pruned,omitempty"`
+}
+
+// syncResult is the outcome for one JDK of the manifest
+type syncResult struct {
+	Name         string `json:"name,omitempty"`
+	Spec         string `json:"spec"`
+	Distribution string `json:"distribution,omitempty"`
+	Version      string `json:"version,omitempty"`
+	// Status is ok, installed, missing (with --dry-run) or failed
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	err error
+}
+
+// fail records why a JDK could not be synced
+func (r *syncResult) fail(err error) {
+	r.Status = "failed"
+	r.Error = err.Error()
+	r.err = err
+}
+
+// pruneResult is the outcome for one installed version not listed in the manifest
+type pruneResult struct {
+	Version string `json:"version"`
+	// Status is removed, would-remove (with --dry-run) or kept
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// syncJob installs one version for all manifest entries that resolved to it
+type syncJob struct {
+	version *registry.JavaVersion
+	results []*syncResult
+}
+
+func init() {
+	syncCmd.Flags().StringVarP(&syncFile, "file", "f", "", "Manifest to sync instead of the project's jvt.toml")
+	syncCmd.Flags().BoolVar(&syncPrune, "prune", false, "Remove installed versions the manifest doesn't list")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Report what would change without installing or removing anything")
+}
+
+// findManifest returns the manifest given with --file, or the nearest jvt.toml
+func findManifest() (string, error) {
+	if syncFile != "" {
+		return syncFile, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	path, err := project.FindManifest(cwd)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", fmt.Errorf("no %s found in %s or its parents", project.ManifestFileName, cwd)
+	}
+	return path, nil
+}
+
+// installedMatch returns the newest installed version satisfying a manifest
+// entry, or "" if none does
+func installedMatch(installer *install.Installer, jdk project.ManifestJDK) string {
+	spec := expandAlias(jdk.Version)
+	resolver, err := newInstalledResolver(installer, spec)
+	if err != nil {
+		return ""
+	}
+
+	for _, v := range resolver.Matches(spec) {
+		if jdk.Distribution == "" {
+			return v
+		}
+		if meta, err := installer.ReadMetadata(v); err == nil && strings.EqualFold(meta.Distribution, jdk.Distribution) {
+			return v
+		}
+	}
+	return ""
+}
+
+// syncMissing resolves the entries without a status against the catalogue and
+// installs them, downloading concurrently
+func syncMissing(ctx context.Context, cfg *config.Config, installer *install.Installer, manifest *project.Manifest, results []syncResult) error {
+	var pending []int
+	for idx := range results {
+		if results[idx].Status == "" {
+			pending = append(pending, idx)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	fmt.Println("Fetching available versions...")
+	reg := newRegistry(cfg)
+	if err := reg.FetchAvailableVersions(ctx); err != nil {
+		return fmt.Errorf("failed to fetch versions: %w", err)
+	}
+
+	// Entries resolving to the same version share one install
+	var jobs []*syncJob
+	byVersion := map[string]*syncJob{}
+	for _, idx := range pending {
+		jdk, result := manifest.JDKs[idx], &results[idx]
+
+		v, err := reg.FindVersion(expandAlias(jdk.Version))
+		if err != nil {
+			result.fail(fmt.Errorf("version not found: %w", err))
+			continue
+		}
+		if jdk.Distribution != "" && !strings.EqualFold(jdk.Distribution, v.Distribution) {
+			result.fail(fmt.Errorf("Java %s is not available from %s (the catalogue provides %s)", jdk.Version, jdk.Distribution, v.Distribution))
+			continue
+		}
+
+		result.Version = v.Version
+		switch {
+		case installer.IsInstalled(v.Version):
+			result.Status = "ok"
+		case syncDryRun:
+			result.Status = "missing"
+		case byVersion[v.Version] != nil:
+			byVersion[v.Version].results = append(byVersion[v.Version].results, result)
+		default:
+			job := &syncJob{version: v, results: []*syncResult{result}}
+			byVersion[v.Version] = job
+			jobs = append(jobs, job)
+		}
+	}
+
+	versions := make([]*registry.JavaVersion, len(jobs))
+	for idx, job := range jobs {
+		versions[idx] = job.version
+	}
+
+	errs := installConcurrently(ctx, cfg, installer, versions)
+	for idx, job := range jobs {
+		for _, result := range job.results {
+			if errs[idx] != nil {
+				result.fail(errs[idx])
+			} else {
+				result.Status = "installed"
+			}
+		}
+	}
+
+	return ctx.Err()
+}
+
+// pruneUnlisted removes installed versions no manifest entry resolved to,
+// keeping those that are still in use
+func pruneUnlisted(ctx context.Context, cfg *config.Config, installer *install.Installer, results []syncResult) ([]pruneResult, error) {
+	listed := map[string]bool{}
+	for _, r := range results {
+		listed[r.Version] = true
+	}
+
+	installed, err := installer.ListInstalled()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed versions: %w", err)
+	}
+
+	var pruned []pruneResult
+	for _, v := range installed {
+		if listed[v] {
+			continue
+		}
+
+		if blockers := findRemovalBlockers(cfg, installer, v); len(blockers) > 0 {
+			reason := describeBlockers(blockers)
+			fmt.Printf("  Keeping Java %s: %s\n", v, reason)
+			pruned = append(pruned, pruneResult{Version: v, Status: "kept", Reason: reason})
+			continue
+		}
+
+		if syncDryRun {
+			fmt.Printf("- Java %s would be removed\n", v)
+			pruned = append(pruned, pruneResult{Version: v, Status: "would-remove"})
+			continue
+		}
+
+		if err := installer.Uninstall(ctx, v); err != nil {
+			return pruned, fmt.Errorf("failed to remove Java %s: %w", v, err)
+		}
+		fmt.Printf("- Java %s removed\n", v)
+		pruned = append(pruned, pruneResult{Version: v, Status: "removed"})
+	}
+
+	return pruned, nil
+}
+
+// syncLabel describes a manifest entry by its name and version spec
+func syncLabel(jdk project.ManifestJDK) string {
+	if jdk.Name == "" {
+		return jdk.Version
+	}
+	return fmt.Sprintf("%s (%s)", jdk.Name, jdk.Version)
+}
@@ -2,13 +2,14 @@ package cli
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/rexqwer911/jvt/internal/config"
 	"github.com/rexqwer911/jvt/internal/install"
 	"github.com/spf13/cobra"
 )
 
+var uninstallForce bool
+
 var uninstallCmd = &cobra.Command{
 	Use:     "uninstall <version>",
 	Short:   "Uninstall a specific Java version",
@@ -23,42 +24,35 @@ var uninstallCmd = &cobra.Command{
 			return fmt.Errorf("failed to get config: %w", err)
 		}
 
+		// Removing files needs an unambiguous match
 		installer := install.NewInstaller(cfg.InstallDir)
-		versions, err := installer.ListInstalled()
+		matchedVersion, err := resolveInstalledUnique(installer, versionStr)
 		if err != nil {
-			return fmt.Errorf("failed to list installed versions: %w", err)
-		}
-
-		// Find matching version
-		var matchedVersion string
-		if _, err := strconv.Atoi(versionStr); err == nil {
-			for _, v := range versions {
-				if len(v) >= len(versionStr) && v[0:len(versionStr)] == versionStr {
-					matchedVersion = v
-					break
-				}
-			}
-		} else {
-			for _, v := range versions {
-				if v == versionStr {
-					matchedVersion = v
-					break
-				}
-			}
+			return err
 		}
 
-		if matchedVersion == "" {
-			return fmt.Errorf("version %s is not installed", versionStr)
+		// Don't pull the rug out from under the active shell or a project
+		if err := checkRemovalAllowed(cmd.Context(), cfg, installer, matchedVersion, uninstallForce); err != nil {
+			return err
 		}
 
 		// Confirm and uninstall
 		fmt.Printf("Uninstalling Java %s...\n", matchedVersion)
-		if err := installer.Uninstall(matchedVersion); err != nil {
+		if err := installer.Uninstall(cmd.Context(), matchedVersion); err != nil {
 			return fmt.Errorf("uninstall failed: %w", err)
 		}
 
 		fmt.Printf("✓ Java %s uninstalled successfully!\n", matchedVersion)
 
-		return nil
+		// A forced removal also drops the pin of the removed build
+		if err := dropPin(cmd.Context(), cfg, matchedVersion); err != nil {
+			fmt.Printf("Warning: failed to remove the pin of Java %s: %v\n", matchedVersion, err)
+		}
+
+		return emit(actionResult{Version: matchedVersion, Status: "uninstalled"})
 	},
 }
+
+func init() {
+	uninstallCmd.Flags().BoolVar(&uninstallForce, "force", false, "Uninstall even if the version is active, the default, pinned, or required by a project")
+}
@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/rexqwer911/jvt/internal/config"
-	"github.com/rexqwer911/jvt/internal/download"
 	"github.com/rexqwer911/jvt/internal/install"
 	"github.com/rexqwer911/jvt/internal/registry"
 	"github.com/rexqwer911/jvt/internal/version"
@@ -13,9 +15,13 @@ import (
 )
 
 var (
-	upgradeAll     bool
-	upgradeDryRun  bool
-	upgradeKeepOld bool
+	upgradeAll                     bool
+	upgradeDryRun                  bool
+	upgradeKeepOld                 bool
+	upgradePatchOnly               bool
+	upgradeKeep                    int
+	upgradeSwitch                  string
+	upgradeAllowDistributionChange bool
 )
 
 var upgradeCmd = &cobra.Command{
@@ -23,10 +29,29 @@ var upgradeCmd = &cobra.Command{
 	Short: "Upgrade Java to the latest version",
 	Long: `Upgrade installed Java versions to the latest available version.
 
+With --all, every installed major is checked against one catalogue fetch and
+the resulting plan is printed first. The new builds are then downloaded in
+parallel, and installed and switched to one at a time.
+
+By default the newest build of each major is replaced by the latest one, the
+environment is switched if the replaced build was active or the default, and the
+replaced build is removed. The "upgrade" section of ~/.jvt/config.json sets a
+different policy, and the flags below override it for one run:
+
+  --patch-only                 Only take patch and security updates of the
+                               installed minor release
+  --keep N                     Keep the newest N builds of each major, the new
+                               one included (0 removes just the replaced build)
+  --switch POLICY              auto: switch when the active or default version
+                               has the upgraded major; if-active: only when it
+                               is the replaced build; never: leave it alone
+  --allow-distribution-change  Replace a build with one of another distribution
+
 Examples:
-  jvt upgrade 17              # Upgrade Java 17 to latest
-  jvt upgrade --all           # Upgrade all installed versions
-  jvt upgrade --all --dry-run # Check for updates without installing`,
+  jvt upgrade 17                      # Upgrade Java 17 to latest
+  jvt upgrade --all                   # Upgrade all installed versions
+  jvt upgrade --all --dry-run         # Check for updates without installing
+  jvt upgrade --all --patch-only --switch never --keep 2   # Conservative build agent`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.GetConfig()
@@ -34,24 +59,32 @@ Examples:
 			return fmt.Errorf("failed to get config: %w", err)
 		}
 
+		policy, err := upgradePolicy(cmd, cfg)
+		if err != nil {
+			return err
+		}
+
 		installer := install.NewInstaller(cfg.InstallDir)
 
+		// One registry for the whole run, so the catalogue is fetched at most once
+		reg := newRegistry(cfg)
+
 		// Handle --all flag
 		if upgradeAll {
-			return upgradeAllVersions(cfg, installer)
+			return upgradeAllVersions(cmd.Context(), cfg, installer, reg, policy)
 		}
 
 		// Require version argument if not using --all
 		if len(args) == 0 {
-			return fmt.Errorf("please specify a major version or use --all flag")
+			return usageErrorf("please specify a major version or use --all flag")
 		}
 
 		majorVersion, err := strconv.Atoi(args[0])
 		if err != nil {
-			return fmt.Errorf("invalid major version: %s", args[0])
+			return usageErrorf("invalid major version: %s", args[0])
 		}
 
-		return upgradeVersion(cfg, installer, majorVersion)
+		return upgradeVersion(cmd.Context(), cfg, installer, reg, policy, majorVersion)
 	},
 }
 
@@ -59,10 +92,48 @@ func init() {
 	upgradeCmd.Flags().BoolVar(&upgradeAll, "all", false, "Upgrade all installed Java versions")
 	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "Check for updates without installing")
 	upgradeCmd.Flags().BoolVar(&upgradeKeepOld, "keep-old", false, "Keep old version after upgrade")
+	upgradeCmd.Flags().BoolVar(&upgradePatchOnly, "patch-only", false, "Only upgrade to patch and security updates of the installed minor release")
+	upgradeCmd.Flags().IntVar(&upgradeKeep, "keep", 0, "Number of builds to keep per major version, the new one included")
+	upgradeCmd.Flags().StringVar(&upgradeSwitch, "switch", config.SwitchIfActive, "When to switch to the new build: auto, if-active or never")
+	upgradeCmd.Flags().BoolVar(&upgradeAllowDistributionChange, "allow-distribution-change", false, "Allow replacing a build with one of another distribution")
+	upgradeCmd.RegisterFlagCompletionFunc("switch", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{config.SwitchAuto, config.SwitchIfActive, config.SwitchNever}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// upgradePolicy returns the configured upgrade policy with the flags given on
+// the command line applied on top
+func upgradePolicy(cmd *cobra.Command, cfg *config.Config) (*config.UpgradePolicy, error) {
+	policy := cfg.Upgrade
+	flags := cmd.Flags()
+
+	if flags.Changed("patch-only") {
+		policy.PatchOnly = upgradePatchOnly
+	}
+	if flags.Changed("keep") {
+		if upgradeKeep < 0 {
+			return nil, usageErrorf("invalid --keep %d: must not be negative", upgradeKeep)
+		}
+		policy.Keep = upgradeKeep
+	}
+	if flags.Changed("switch") {
+		if !config.ValidSwitch(upgradeSwitch) {
+			return nil, usageErrorf("invalid --switch %q (expected %s, %s or %s)", upgradeSwitch, config.SwitchAuto, config.SwitchIfActive, config.SwitchNever)
+		}
+		policy.Switch = upgradeSwitch
+	}
+	if flags.Changed("allow-distribution-change") {
+		policy.AllowDistributionChange = upgradeAllowDistributionChange
+	}
+
+	return &policy, nil
 }
 
-// upgradeAllVersions upgrades all installed major versions
-func upgradeAllVersions(cfg *config.Config, installer *install.Installer) error {
+// upgradeAllVersions upgrades all installed major versions. Every major is
+// planned against one catalogue fetch and the plan is printed before anything
+// changes; the new builds are then downloaded concurrently and installed and
+// switched to one at a time.
+func upgradeAllVersions(ctx context.Context, cfg *config.Config, installer *install.Installer, reg *registry.Registry, policy *config.UpgradePolicy) error {
 	majorVersions, err := installer.GetInstalledMajorVersions()
 	if err != nil {
 		return fmt.Errorf("failed to get installed versions: %w", err)
@@ -70,32 +141,60 @@ func upgradeAllVersions(cfg *config.Config, installer *install.Installer) error
 
 	if len(majorVersions) == 0 {
 		fmt.Println("No Java versions installed.")
-		return nil
+		return emit(upgradeReport{Upgrades: []upgradeDecision{}})
 	}
 
+	fmt.Println("Checking for Java updates...")
 	if upgradeDryRun {
-		fmt.Println("Checking for Java updates...")
 		fmt.Println()
 	}
 
-	hasUpdates := false
-	var updateCount int
-	var upToDateCount int
+	if err := reg.FetchAvailableVersions(ctx); err != nil {
+		return fmt.Errorf("failed to fetch versions: %w", err)
+	}
 
-	for _, major := range majorVersions {
-		result, err := checkAndUpgradeVersion(cfg, installer, major)
+	report := upgradeReport{Upgrades: make([]upgradeDecision, len(majorVersions))}
+	var plans []*upgradePlan
+	for idx, major := range majorVersions {
+		decision, plan, err := planUpgrade(ctx, cfg, installer, reg, policy, major)
 		if err != nil {
 			fmt.Printf("Error checking Java %d: %v\n", major, err)
-			continue
+			decision.Error = err.Error()
+		}
+		report.Upgrades[idx] = decision
+		if plan != nil {
+			plan.decision = &report.Upgrades[idx]
+			plans = append(plans, plan)
+		}
+	}
+
+	if !upgradeDryRun && len(plans) > 0 {
+		fmt.Println("\nUpgrade plan:")
+		for _, plan := range plans {
+			fmt.Printf("  Java %d: %s → %s\n", plan.decision.Major, plan.decision.Installed, plan.decision.Latest)
+		}
+		fmt.Println()
+
+		runUpgradePlans(ctx, cfg, installer, policy, plans)
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+	}
 
-		if result == "updated" {
+	hasUpdates := false
+	var updateCount int
+	var upToDateCount int
+	var heldCount int
+	for _, decision := range report.Upgrades {
+		if decision.Status == "updated" {
 			updateCount++
 			hasUpdates = true
-		} else if result == "available" {
+		} else if decision.Status == "available" {
 			hasUpdates = true
-		} else if result == "up-to-date" {
+		} else if decision.Status == "up-to-date" {
 			upToDateCount++
+		} else if decision.Status == "held" {
+			heldCount++
 		}
 	}
 
@@ -115,37 +214,142 @@ func upgradeAllVersions(cfg *config.Config, installer *install.Installer) error
 			fmt.Printf("  %d version(s) already up to date.\n", upToDateCount)
 		}
 	}
+	if heldCount > 0 {
+		fmt.Printf("  %d version(s) held back by the upgrade policy.\n", heldCount)
+	}
 
-	return nil
+	return emit(report)
+}
+
+// runUpgradePlans downloads the new builds of several plans concurrently, then
+// installs them and switches to them one plan at a time. Failures are recorded
+// in the plan's decision.
+func runUpgradePlans(ctx context.Context, cfg *config.Config, installer *install.Installer, policy *config.UpgradePolicy, plans []*upgradePlan) {
+	versions := make([]*registry.JavaVersion, len(plans))
+	for idx, plan := range plans {
+		versions[idx] = plan.target
+	}
+
+	archives, errs := downloadConcurrently(ctx, cfg, versions)
+	for idx, plan := range plans {
+		err := errs[idx]
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err == nil {
+			fmt.Printf("\nUpgrading Java %d (%s → %s)...\n", plan.decision.Major, plan.decision.Installed, plan.target.Version)
+			err = applyUpgrade(ctx, cfg, installer, policy, plan, archives[idx])
+		}
+		if err != nil {
+			fmt.Printf("Error upgrading Java %d: %v\n", plan.decision.Major, err)
+			plan.decision.Status = "error"
+			plan.decision.Error = err.Error()
+		}
+	}
 }
 
 // upgradeVersion upgrades a specific major version
-func upgradeVersion(cfg *config.Config, installer *install.Installer, majorVersion int) error {
-	result, err := checkAndUpgradeVersion(cfg, installer, majorVersion)
+func upgradeVersion(ctx context.Context, cfg *config.Config, installer *install.Installer, reg *registry.Registry, policy *config.UpgradePolicy, majorVersion int) error {
+	decision, err := checkAndUpgradeVersion(ctx, cfg, installer, reg, policy, majorVersion)
 	if err != nil {
 		return err
 	}
 
-	if result == "not-installed" {
-		return fmt.Errorf("Java %d is not installed. Use 'jvt install %d' first", majorVersion, majorVersion)
+	if decision.Status == "not-installed" {
+		spec := strconv.Itoa(majorVersion)
+		return fmt.Errorf("%w. Use 'jvt install %s' first", &version.NotInstalledError{Spec: spec}, spec)
 	}
 
-	return nil
+	return emit(upgradeReport{Upgrades: []upgradeDecision{decision}})
+}
+
+// upgradeDecision is the outcome of checking one major version for updates.
+// Status is one of "updated", "available", "up-to-date", "held", "pinned",
+// "not-installed", "error"; a held update is one the upgrade policy rules out.
+type upgradeDecision struct {
+	Major     int      `json:"major"`
+	Installed string   `json:"installed,omitempty"`
+	Latest    string   `json:"latest,omitempty"`
+	Status    string   `json:"status"`
+	Held      string   `json:"held_reason,omitempty"`
+	Switched  bool     `json:"switched"`
+	Removed   bool     `json:"removed"`
+	Kept      string   `json:"kept_reason,omitempty"`
+	Pruned    []string `json:"pruned,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// upgradeReport is the structured output of upgrade
+type upgradeReport struct {
+	Upgrades []upgradeDecision `json:"upgrades"`
+}
+
+// upgradePlan is an upgrade of one major version decided by planUpgrade
+type upgradePlan struct {
+	decision *upgradeDecision
+	target   *registry.JavaVersion
+	// upgradable are the unpinned installed builds of the major
+	upgradable []string
 }
 
 // checkAndUpgradeVersion checks and optionally upgrades a single major version
-// Returns: "updated", "available", "up-to-date", "not-installed", "error"
-func checkAndUpgradeVersion(cfg *config.Config, installer *install.Installer, majorVersion int) (string, error) {
+func checkAndUpgradeVersion(ctx context.Context, cfg *config.Config, installer *install.Installer, reg *registry.Registry, policy *config.UpgradePolicy, majorVersion int) (upgradeDecision, error) {
+	decision, plan, err := planUpgrade(ctx, cfg, installer, reg, policy, majorVersion)
+	if err != nil || plan == nil || upgradeDryRun {
+		return decision, err
+	}
+	plan.decision = &decision
+
+	fmt.Printf("\nUpgrading Java %d...\n", majorVersion)
+	fmt.Printf("  Current version: %s\n", decision.Installed)
+	fmt.Printf("  Latest version:  %s\n", plan.target.Version)
+	fmt.Println()
+
+	// Download
+	downloader := newDownloader(cfg)
+	fmt.Printf("Downloading from: %s\n", plan.target.DownloadURL)
+
+	archivePath, err := downloader.DownloadAndVerify(
+		ctx,
+		plan.target.DownloadURL,
+		plan.target.FileName,
+		plan.target.Checksum,
+	)
+	if err != nil {
+		return decision, fmt.Errorf("download failed: %w", err)
+	}
+	fmt.Println()
+
+	err = applyUpgrade(ctx, cfg, installer, policy, plan, archivePath)
+	return decision, err
+}
+
+// planUpgrade checks a single major version for updates under the policy and
+// returns the plan to upgrade it, or nil if there is nothing to upgrade
+func planUpgrade(ctx context.Context, cfg *config.Config, installer *install.Installer, reg *registry.Registry, policy *config.UpgradePolicy, majorVersion int) (upgradeDecision, *upgradePlan, error) {
+	decision := upgradeDecision{Major: majorVersion, Status: "error"}
+
 	// Get installed versions for this major version
 	installedVersions, err := installer.GetInstalledByMajor(majorVersion)
 	if err != nil {
-		return "error", fmt.Errorf("failed to get installed versions: %w", err)
+		return decision, nil, fmt.Errorf("failed to get installed versions: %w", err)
 	}
 
 	if len(installedVersions) == 0 {
-		return "not-installed", nil
+		decision.Status = "not-installed"
+		return decision, nil, nil
 	}
 
+	// Pinned builds are never replaced; only the others of the major are upgraded
+	unpinned := unpinnedVersions(cfg, installedVersions)
+	if len(unpinned) == 0 {
+		fmt.Printf("Java %d is pinned (%s); skipping\n", majorVersion, strings.Join(installedVersions, ", "))
+		decision.Installed = installedVersions[0]
+		decision.Status = "pinned"
+		return decision, nil, nil
+	}
+	installedVersions = unpinned
+
 	// Find the newest installed version
 	newestInstalled := installedVersions[0]
 	for _, v := range installedVersions {
@@ -157,94 +361,103 @@ func checkAndUpgradeVersion(cfg *config.Config, installer *install.Installer, ma
 			newestInstalled = v
 		}
 	}
+	decision.Installed = newestInstalled
 
 	// Fetch latest available version
-	reg := registry.NewRegistry()
-	if !upgradeDryRun {
+	if !upgradeDryRun && !upgradeAll {
 		fmt.Printf("Checking for Java %d updates...\n", majorVersion)
 	}
 
-	latestAvailable, err := reg.FindLatestForMajor(majorVersion)
+	latestAvailable, err := reg.FindLatestForMajor(ctx, majorVersion)
 	if err != nil {
-		return "error", fmt.Errorf("failed to fetch latest version: %w", err)
+		return decision, nil, fmt.Errorf("failed to fetch latest version: %w", err)
+	}
+	decision.Latest = latestAvailable.Version
+
+	// With patch-only, the target is the newest build of the installed minor release
+	var patch *registry.JavaVersion
+	if policy.PatchOnly {
+		if patch, err = latestPatch(ctx, reg, majorVersion, newestInstalled); err != nil {
+			return decision, nil, err
+		}
 	}
 
-	// Compare versions
-	cmp, err := version.CompareVersions(newestInstalled, latestAvailable.Version)
+	target, held, err := upgradeTarget(newestInstalled, latestAvailable, patch, policy.PatchOnly)
 	if err != nil {
-		return "error", fmt.Errorf("failed to compare versions: %w", err)
+		return decision, nil, err
 	}
 
-	if cmp >= 0 {
+	if held != "" {
+		fmt.Printf("Java %d: %s → %s held back (%s)\n", majorVersion, newestInstalled, latestAvailable.Version, held)
+		decision.Status = "held"
+		decision.Held = held
+		return decision, nil, nil
+	}
+
+	if target == nil {
 		// Already up to date
 		if upgradeDryRun || upgradeAll {
 			fmt.Printf("Java %d is up to date (%s)\n", majorVersion, newestInstalled)
 		} else {
 			fmt.Printf("Java %d is already up to date (%s)\n", majorVersion, newestInstalled)
 		}
-		return "up-to-date", nil
+		decision.Status = "up-to-date"
+		return decision, nil, nil
+	}
+
+	decision.Latest = target.Version
+	latestAvailable = target
+
+	// Builds of another distribution only replace this one when the policy allows it
+	if reason := distributionChange(installer, newestInstalled, latestAvailable); reason != "" && !policy.AllowDistributionChange {
+		reason += "; use --allow-distribution-change to accept it"
+		fmt.Printf("Java %d: %s → %s held back (%s)\n", majorVersion, newestInstalled, latestAvailable.Version, reason)
+		decision.Status = "held"
+		decision.Held = reason
+		return decision, nil, nil
 	}
 
 	// Update available
+	decision.Status = "available"
 	if upgradeDryRun {
 		fmt.Printf("Java %d: %s → %s (update available)\n", majorVersion, newestInstalled, latestAvailable.Version)
-		return "available", nil
-	}
-
-	// Perform upgrade
-	fmt.Printf("\nUpgrading Java %d...\n", majorVersion)
-	fmt.Printf("  Current version: %s\n", newestInstalled)
-	fmt.Printf("  Latest version:  %s\n", latestAvailable.Version)
-	fmt.Println()
-
-	// Check if current version is active
-	mgr := version.NewManager(cfg.InstallDir)
-	isActive, err := mgr.IsVersionActive(newestInstalled)
-	if err != nil {
-		fmt.Printf("Warning: Failed to determine if current version %s is active: %v\n", newestInstalled, err)
-		isActive = false
 	}
+	return decision, &upgradePlan{target: latestAvailable, upgradable: installedVersions}, nil
+}
 
-	// Download
-	downloader := download.NewDownloader(cfg.CacheDir)
-	fmt.Printf("Downloading from: %s\n", latestAvailable.DownloadURL)
+// applyUpgrade installs the downloaded build of a plan, switches to it as the
+// policy says and removes the old builds the policy doesn't keep
+func applyUpgrade(ctx context.Context, cfg *config.Config, installer *install.Installer, policy *config.UpgradePolicy, plan *upgradePlan, archivePath string) error {
+	decision, target := plan.decision, plan.target
+	replaced := decision.Installed
 
-	archivePath, err := downloader.DownloadAndVerify(
-		latestAvailable.DownloadURL,
-		latestAvailable.FileName,
-		latestAvailable.Checksum,
-	)
-	if err != nil {
-		return "error", fmt.Errorf("download failed: %w", err)
-	}
+	// Decide before installing whether the new build takes over the environment
+	mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+	isActive := shouldSwitch(mgr, policy, plan.upgradable, replaced)
 
 	// Install
-	fmt.Println("\nInstalling...")
-	if err := installer.Install(archivePath, latestAvailable.Version); err != nil {
-		return "error", fmt.Errorf("installation failed: %w", err)
+	fmt.Println("Installing...")
+	if err := installer.Install(ctx, archivePath, newInstallMetadata(target)); err != nil {
+		return fmt.Errorf("installation failed: %w", err)
 	}
 
 	// If the old version was active, set environment to the new version
 	if isActive {
-		if err := mgr.SetUserEnvironment(latestAvailable.Version); err != nil {
+		if err := mgr.SetUserEnvironment(ctx, target.Version); err != nil {
 			fmt.Printf("Warning: Failed to set environment: %v\n", err)
 		} else {
 			fmt.Println("✓ Java version updated")
+			decision.Switched = true
 		}
 	}
 
-	// Remove old version unless --keep-old
+	// Remove old versions unless --keep-old; the keep policy and anything that
+	// still depends on a build decide which ones go
 	if !upgradeKeepOld {
-		fmt.Printf("Removing old version %s...\n", newestInstalled)
-		if err := installer.Uninstall(newestInstalled); err != nil {
-			fmt.Printf("Warning: Failed to remove old version: %v\n", err)
-			fmt.Printf("You can manually remove it with: jvt uninstall %s\n", newestInstalled)
-		} else {
-			fmt.Println("✓ Old version removed")
-		}
+		removeOldBuilds(ctx, cfg, installer, policy, plan.upgradable, replaced, decision)
 	}
 
-	fmt.Printf("\n✓ Java %d upgraded successfully! (%s → %s)\n", majorVersion, newestInstalled, latestAvailable.Version)
+	fmt.Printf("\n✓ Java %d upgraded successfully! (%s → %s)\n", decision.Major, replaced, target.Version)
 
 	if isActive {
 		fmt.Println("\nPlease restart your terminal or run:")
@@ -252,5 +465,159 @@ func checkAndUpgradeVersion(cfg *config.Config, installer *install.Installer, ma
 		fmt.Println("  or open a new terminal window")
 	}
 
-	return "updated", nil
+	decision.Status = "updated"
+	return nil
+}
+
+// upgradeRemovalBlocker returns why the old build must be kept after an upgrade,
+// or an empty string if it can be removed. When the environment was just switched
+// to the new build, the active/default checks no longer apply.
+func upgradeRemovalBlocker(cfg *config.Config, installer *install.Installer, oldVersion string, switched bool) string {
+	var remaining []removalBlocker
+	for _, b := range findRemovalBlockers(cfg, installer, oldVersion) {
+		if switched && b.switchable {
+			continue
+		}
+		remaining = append(remaining, b)
+	}
+
+	if len(remaining) == 0 {
+		return ""
+	}
+	return describeBlockers(remaining)
+}
+
+// upgradeTarget returns the build the newest installed build of a major is
+// upgraded to: the latest build or, with patch-only, patch, the newest build
+// of the installed minor release. It returns nil if there is nothing to
+// upgrade to, with the reason when the policy holds a newer build back.
+func upgradeTarget(installed string, latest, patch *registry.JavaVersion, patchOnly bool) (*registry.JavaVersion, string, error) {
+	target := latest
+	if patchOnly {
+		target = patch
+	}
+
+	if target != nil {
+		cmp, err := version.CompareVersions(installed, target.Version)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to compare versions: %w", err)
+		}
+		if cmp < 0 {
+			return target, "", nil
+		}
+	}
+
+	if patchOnly {
+		if cmp, err := version.CompareVersions(installed, latest.Version); err == nil && cmp < 0 {
+			return nil, fmt.Sprintf("%s is a new minor release and the policy allows patch updates only", latest.Version), nil
+		}
+	}
+	return nil, "", nil
+}
+
+// latestPatch returns the newest release of the same minor release as the
+// installed version, or nil if there is none. The catalogue only holds the
+// latest build of each major, so the release list of the major is consulted.
+func latestPatch(ctx context.Context, reg *registry.Registry, majorVersion int, installed string) (*registry.JavaVersion, error) {
+	releases, err := reg.FindReleasesForMajor(ctx, majorVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range releases {
+		if same, err := version.SameMinor(installed, v.Version); err == nil && same {
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
+// distributionChange describes how the distribution of the latest build differs
+// from the installed one, or returns an empty string if it doesn't
+func distributionChange(installer *install.Installer, installed string, latest *registry.JavaVersion) string {
+	meta, err := installer.ReadMetadata(installed)
+	if err != nil || meta.Distribution == "" || strings.EqualFold(meta.Distribution, latest.Distribution) {
+		return ""
+	}
+	return fmt.Sprintf("the latest build is from %s, not %s", latest.Distribution, meta.Distribution)
+}
+
+// shouldSwitch applies the switch policy: if-active switches when the replaced
+// build is active or the default, auto when any upgradable build of the major is
+func shouldSwitch(mgr *version.Manager, policy *config.UpgradePolicy, upgradable []string, replaced string) bool {
+	inUse := map[string]bool{}
+	if current, err := mgr.GetCurrentVersion(); err == nil {
+		inUse[current] = true
+	}
+	if def, err := mgr.GetDefaultVersion(); err == nil {
+		inUse[def] = true
+	}
+
+	switch policy.Switch {
+	case config.SwitchNever:
+		return false
+	case config.SwitchAuto:
+		for _, v := range upgradable {
+			if inUse[v] {
+				return true
+			}
+		}
+		return false
+	default:
+		return inUse[replaced]
+	}
+}
+
+// removeOldBuilds removes the builds of a major the keep policy no longer
+// retains. With a keep count of 0 only the replaced build goes; otherwise the
+// newest builds are kept, the new one counting towards the limit. Pinned builds
+// are never among the candidates.
+func removeOldBuilds(ctx context.Context, cfg *config.Config, installer *install.Installer, policy *config.UpgradePolicy, upgradable []string, replaced string, decision *upgradeDecision) {
+	candidates := []string{replaced}
+	if policy.Keep > 0 {
+		candidates = newestFirst(upgradable)
+		// The new build takes one of the kept slots
+		keepOld := min(policy.Keep-1, len(candidates))
+		for _, v := range candidates[:keepOld] {
+			if v == replaced {
+				decision.Kept = fmt.Sprintf("the keep policy retains %d build(s)", policy.Keep)
+				fmt.Printf("Keeping old version %s: %s\n", v, decision.Kept)
+			}
+		}
+		candidates = candidates[keepOld:]
+	}
+
+	for _, v := range candidates {
+		// Only the replaced build handed the environment over to the new one
+		if reason := upgradeRemovalBlocker(cfg, installer, v, decision.Switched && v == replaced); reason != "" {
+			fmt.Printf("Keeping old version %s: %s\n", v, reason)
+			if v == replaced {
+				decision.Kept = reason
+			}
+			continue
+		}
+
+		fmt.Printf("Removing old version %s...\n", v)
+		if err := installer.Uninstall(ctx, v); err != nil {
+			fmt.Printf("Warning: Failed to remove old version: %v\n", err)
+			fmt.Printf("You can manually remove it with: jvt uninstall %s\n", v)
+			continue
+		}
+		fmt.Println("✓ Old version removed")
+		if v == replaced {
+			decision.Removed = true
+		} else {
+			decision.Pruned = append(decision.Pruned, v)
+		}
+	}
+}
+
+// newestFirst returns the versions sorted from newest to oldest
+func newestFirst(versions []string) []string {
+	sorted := append([]string(nil), versions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		cmp, err := version.CompareVersions(sorted[i], sorted[j])
+		return err == nil && cmp > 0
+	})
+	return sorted
 }
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/rexqwer911/jvt/internal/state"
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+func TestUpgradeTarget(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed string
+		latest    string
+		patch     string
+		patchOnly bool
+		want      string
+		wantHeld  bool
+	}{
+		{name: "newer build", installed: "17.0.9+9", latest: "17.0.10+7", want: "17.0.10+7"},
+		{name: "newer minor release", installed: "17.0.9+9", latest: "17.1.0+3", want: "17.1.0+3"},
+		{name: "up to date", installed: "17.0.10+7", latest: "17.0.10+7"},
+		{name: "installed build is newer", installed: "17.0.11+1", latest: "17.0.10+7"},
+		{name: "patch-only takes the newest patch", installed: "17.0.9+9", latest: "17.1.0+3", patch: "17.0.10+7", patchOnly: true, want: "17.0.10+7"},
+		{name: "patch-only holds a new minor release back", installed: "17.0.10+7", latest: "17.1.0+3", patch: "17.0.10+7", patchOnly: true, wantHeld: true},
+		{name: "patch-only without a release of the minor", installed: "17.0.10+7", latest: "17.1.0+3", patchOnly: true, wantHeld: true},
+		{name: "patch-only and up to date", installed: "17.0.10+7", latest: "17.0.10+7", patch: "17.0.10+7", patchOnly: true},
+		{name: "patch-only ignores the patch without the policy", installed: "17.0.9+9", latest: "17.1.0+3", patch: "17.0.10+7", want: "17.1.0+3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest := &registry.JavaVersion{Version: tt.latest}
+			var patch *registry.JavaVersion
+			if tt.patch != "" {
+				patch = &registry.JavaVersion{Version: tt.patch}
+			}
+
+			target, held, err := upgradeTarget(tt.installed, latest, patch, tt.patchOnly)
+			if err != nil {
+				t.Fatalf("upgradeTarget() error = %v", err)
+			}
+			got := ""
+			if target != nil {
+				got = target.Version
+			}
+			if got != tt.want || (held != "") != tt.wantHeld {
+				t.Errorf("upgradeTarget() = %q, held %q; want %q, held %v", got, held, tt.want, tt.wantHeld)
+			}
+		})
+	}
+}
+
+func TestShouldSwitch(t *testing.T) {
+	tests := []struct {
+		name       string
+		switchMode string
+		active     string
+		def        string
+		want       bool
+	}{
+		{name: "if-active with the replaced build active", switchMode: config.SwitchIfActive, active: "17.0.9+9", want: true},
+		{name: "if-active with the replaced build the default", switchMode: config.SwitchIfActive, def: "17.0.9+9", want: true},
+		{name: "if-active with an older build active", switchMode: config.SwitchIfActive, active: "17.0.6+10", want: false},
+		{name: "if-active with another major active", switchMode: config.SwitchIfActive, active: "21.0.2+13", want: false},
+		{name: "auto with an older build active", switchMode: config.SwitchAuto, active: "17.0.6+10", want: true},
+		{name: "auto with an older build the default", switchMode: config.SwitchAuto, def: "17.0.6+10", want: true},
+		{name: "auto with another major active", switchMode: config.SwitchAuto, active: "21.0.2+13", want: false},
+		{name: "never with the replaced build active", switchMode: config.SwitchNever, active: "17.0.9+9", def: "17.0.9+9", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, _ := newTestInstall(t, []string{"17.0.6+10", "17.0.9+9", "21.0.2+13"}, state.State{Default: tt.def})
+			if tt.active != "" {
+				t.Setenv("JAVA_HOME", filepath.Join(cfg.InstallDir, tt.active))
+			}
+
+			mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+			policy := &config.UpgradePolicy{Switch: tt.switchMode}
+			if got := shouldSwitch(mgr, policy, []string{"17.0.6+10", "17.0.9+9"}, "17.0.9+9"); got != tt.want {
+				t.Errorf("shouldSwitch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveOldBuilds(t *testing.T) {
+	// 17.0.11+9 is the new build; the others are the builds it upgrades
+	upgradable := []string{"17.0.6+10", "17.0.9+9", "17.0.10+7"}
+	replaced := "17.0.10+7"
+
+	tests := []struct {
+		name        string
+		keep        int
+		def         string
+		switched    bool
+		aliases     map[string]string
+		wantLeft    []string
+		wantRemoved bool
+		wantKept    string
+		wantPruned  []string
+	}{
+		{
+			name:        "keep 0 removes only the replaced build",
+			wantLeft:    []string{"17.0.11+9", "17.0.6+10", "17.0.9+9"},
+			wantRemoved: true,
+		},
+		{
+			name:        "keep 1 leaves only the new build",
+			keep:        1,
+			wantLeft:    []string{"17.0.11+9"},
+			wantRemoved: true,
+			wantPruned:  []string{"17.0.9+9", "17.0.6+10"},
+		},
+		{
+			name:       "keep 2 retains the replaced build",
+			keep:       2,
+			wantLeft:   []string{"17.0.10+7", "17.0.11+9"},
+			wantKept:   "keep policy",
+			wantPruned: []string{"17.0.9+9", "17.0.6+10"},
+		},
+		{
+			name:     "keep larger than the builds removes nothing",
+			keep:     5,
+			wantLeft: []string{"17.0.10+7", "17.0.11+9", "17.0.6+10", "17.0.9+9"},
+			wantKept: "keep policy",
+		},
+		{
+			name:     "replaced default is kept without a switch",
+			def:      replaced,
+			wantLeft: []string{"17.0.10+7", "17.0.11+9", "17.0.6+10", "17.0.9+9"},
+			wantKept: "default version",
+		},
+		{
+			name:        "replaced default goes once switched",
+			def:         replaced,
+			switched:    true,
+			wantLeft:    []string{"17.0.11+9", "17.0.6+10", "17.0.9+9"},
+			wantRemoved: true,
+		},
+		{
+			name:        "older default is not released by the switch",
+			keep:        1,
+			def:         "17.0.6+10",
+			switched:    true,
+			wantLeft:    []string{"17.0.11+9", "17.0.6+10"},
+			wantRemoved: true,
+			wantPruned:  []string{"17.0.9+9"},
+		},
+		{
+			name:        "aliased build is kept",
+			keep:        1,
+			aliases:     map[string]string{"legacy": "17.0.9+9"},
+			wantLeft:    []string{"17.0.11+9", "17.0.9+9"},
+			wantRemoved: true,
+			wantPruned:  []string{"17.0.6+10"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installed := append([]string{"17.0.11+9"}, upgradable...)
+			cfg, installer := newTestInstall(t, installed, state.State{Default: tt.def, Aliases: tt.aliases})
+
+			decision := &upgradeDecision{Major: 17, Installed: replaced, Switched: tt.switched}
+			policy := &config.UpgradePolicy{Keep: tt.keep}
+			removeOldBuilds(context.Background(), cfg, installer, policy, upgradable, replaced, decision)
+
+			left, err := installer.ListInstalled()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(left, tt.wantLeft) {
+				t.Errorf("installed after upgrade = %v, want %v", left, tt.wantLeft)
+			}
+			if decision.Removed != tt.wantRemoved {
+				t.Errorf("Removed = %v, want %v", decision.Removed, tt.wantRemoved)
+			}
+			if (decision.Kept == "") != (tt.wantKept == "") || !strings.Contains(decision.Kept, tt.wantKept) {
+				t.Errorf("Kept = %q, want a reason mentioning %q", decision.Kept, tt.wantKept)
+			}
+			if !slices.Equal(decision.Pruned, tt.wantPruned) {
+				t.Errorf("Pruned = %v, want %v", decision.Pruned, tt.wantPruned)
+			}
+		})
+	}
+}
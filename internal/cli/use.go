@@ -1,9 +1,9 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"runtime"
-	"strconv"
 
 	"github.com/rexqwer911/jvt/internal/config"
 	"github.com/rexqwer911/jvt/internal/install"
@@ -24,74 +24,63 @@ var useCmd = &cobra.Command{
 			return fmt.Errorf("failed to get config: %w", err)
 		}
 
-		// Find installed version matching the input
+		// Find the newest installed version matching the input
 		installer := install.NewInstaller(cfg.InstallDir)
-		versions, err := installer.ListInstalled()
+		matchedVersion, err := resolveInstalled(installer, versionStr)
 		if err != nil {
-			return fmt.Errorf("failed to list installed versions: %w", err)
+			return err
 		}
 
-		if len(versions) == 0 {
-			return fmt.Errorf("no Java versions installed. Use 'jvt install <version>' first")
+		if err := switchVersion(cmd.Context(), cfg, matchedVersion); err != nil {
+			return err
 		}
 
-		// Try to find matching version
-		var matchedVersion string
-		if _, err := strconv.Atoi(versionStr); err == nil {
-			// Search by major version
-			for _, v := range versions {
-				if len(v) >= len(versionStr) && v[0:len(versionStr)] == versionStr {
-					matchedVersion = v
-					break
-				}
-			}
-		} else {
-			// Exact match
-			for _, v := range versions {
-				if v == versionStr {
-					matchedVersion = v
-					break
-				}
-			}
-		}
-
-		if matchedVersion == "" {
-			return fmt.Errorf("version %s is not installed", versionStr)
-		}
-
-		// Manage environment
-		mgr := version.NewManager(cfg.InstallDir)
+		fmt.Printf("✓ Now using Java %s\n", matchedVersion)
+		return emit(actionResult{Version: matchedVersion, Status: "active"})
+	},
+}
 
-		// 1. Set Persistent Environment (Registry)
-		// First try User environment (always should succeed)
-		if err := mgr.SetUserEnvironment(matchedVersion); err != nil {
-			return fmt.Errorf("failed to set user environment: %w", err)
+// switchVersion makes an installed version the active and default Java version
+func switchVersion(ctx context.Context, cfg *config.Config, matchedVersion string) error {
+	// Manage environment
+	mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
+
+	// 1. Set Persistent Environment (Registry)
+	// First try User environment (always should succeed)
+	if err := mgr.SetUserEnvironment(ctx, matchedVersion); err != nil {
+		return fmt.Errorf("failed to set user environment: %w", err)
+	}
+
+	// Then try System environment (Windows only)
+	if runtime.GOOS == "windows" {
+		if err := mgr.SetSystemEnvironment(ctx, matchedVersion); err != nil {
+			// Check if it's likely a permission error
+			// On Windows, syscall.ERROR_ACCESS_DENIED is 5
+			fmt.Printf("\nNote: Could not update System environment variables (requires Administrator).\n")
+			fmt.Printf("   Reason: %v\n", err)
+			fmt.Println("   Only User environment variables were updated.")
+		} else {
+			fmt.Println("✓ System environment variables updated.")
 		}
+	}
 
-		// Then try System environment (Windows only)
-		if runtime.GOOS == "windows" {
-			if err := mgr.SetSystemEnvironment(matchedVersion); err != nil {
-				// Check if it's likely a permission error
-				// On Windows, syscall.ERROR_ACCESS_DENIED is 5
-				fmt.Printf("\nNote: Could not update System environment variables (requires Administrator).\n")
-				fmt.Printf("   Reason: %v\n", err)
-				fmt.Println("   Only User environment variables were updated.")
-			} else {
-				fmt.Println("✓ System environment variables updated.")
-			}
-		}
+	// 2. Set Current Session Environment
+	if err := mgr.SetEnvironment(matchedVersion); err != nil {
+		// Warn but don't fail if session update fails (e.g. maybe restricted)
+		// But usually it should work if registry worked?
+		// Actually failure here is annoying for the user.
+		fmt.Printf("Warning: failed to set current session environment: %v\n", err)
+	}
 
-		// 2. Set Current Session Environment
-		if err := mgr.SetEnvironment(matchedVersion); err != nil {
-			// Warn but don't fail if session update fails (e.g. maybe restricted)
-			// But usually it should work if registry worked?
-			// Actually failure here is annoying for the user.
-			fmt.Printf("Warning: failed to set current session environment: %v\n", err)
-		}
+	return nil
+}
 
-		fmt.Printf("✓ Now using Java %s\n", matchedVersion)
-		return nil
-	},
+// currentResult is the structured output of current; Version is empty when
+// no jvt-managed version is active
+type currentResult struct {
+	Version  string `json:"version"`
+	JavaHome string `json:"java_home,omitempty"`
+	Default  string `json:"default,omitempty"`
 }
 
 var currentCmd = &cobra.Command{
@@ -104,8 +93,19 @@ var currentCmd = &cobra.Command{
 			return fmt.Errorf("failed to get config: %w", err)
 		}
 
-		mgr := version.NewManager(cfg.InstallDir)
+		mgr := version.NewManager(cfg.InstallDir, cfg.StateFile)
 		currentVersion, err := mgr.GetCurrentVersion()
+
+		if structuredOutput() {
+			result := currentResult{}
+			if err == nil {
+				result.Version = currentVersion
+				result.JavaHome = install.NewInstaller(cfg.InstallDir).GetJavaHome(currentVersion)
+			}
+			result.Default, _ = mgr.GetDefaultVersion()
+			return emit(result)
+		}
+
 		if err != nil {
 			fmt.Println("No jvt-managed Java version is currently active.")
 			fmt.Println("Use 'jvt use <version>' to activate a version.")
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/spf13/cobra"
+)
+
+var verifyAll bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [version]",
+	Short: "Check installed Java versions for modified files",
+	Long: `Compare installed Java versions against the file manifest recorded at
+install time and report missing, modified or unexpected files.
+
+Examples:
+  jvt verify 17      # Verify the newest installed Java 17
+  jvt verify --all   # Verify every installed version`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		installer := install.NewInstaller(cfg.InstallDir)
+
+		var versions []string
+		if verifyAll {
+			versions, err = installer.ListInstalled()
+			if err != nil {
+				return fmt.Errorf("failed to list installed versions: %w", err)
+			}
+			if len(versions) == 0 {
+				fmt.Println("No Java versions installed.")
+				return emit(verifyReport{Results: []verifyResult{}})
+			}
+		} else {
+			if len(args) == 0 {
+				return usageErrorf("please specify a version or use --all flag")
+			}
+			matchedVersion, err := resolveInstalled(installer, args[0])
+			if err != nil {
+				return err
+			}
+			versions = []string{matchedVersion}
+		}
+
+		failed := 0
+		report := verifyReport{Results: []verifyResult{}}
+		for _, v := range versions {
+			problems, err := installer.Verify(v)
+			if err != nil {
+				if os.IsNotExist(err) {
+					fmt.Printf("? Java %s: no file manifest recorded (installed by an older jvt)\n", v)
+					report.Results = append(report.Results, verifyResult{Version: v, Status: "no-manifest"})
+				} else {
+					fmt.Printf("✗ Java %s: %v\n", v, err)
+					report.Results = append(report.Results, verifyResult{Version: v, Status: "error", Error: err.Error()})
+				}
+				failed++
+				continue
+			}
+
+			if len(problems) == 0 {
+				fmt.Printf("✓ Java %s: all files match\n", v)
+				report.Results = append(report.Results, verifyResult{Version: v, Status: "ok"})
+				continue
+			}
+
+			failed++
+			report.Results = append(report.Results, verifyResult{Version: v, Status: "modified", Problems: problems})
+			fmt.Printf("✗ Java %s: %d file(s) differ\n", v, len(problems))
+			for _, p := range problems {
+				fmt.Printf("    %s: %s\n", p.Path, p.Problem)
+			}
+			fmt.Printf("  Run 'jvt repair %s' to restore it.\n", v)
+		}
+
+		if err := emit(report); err != nil {
+			return err
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d version(s) failed verification", failed)
+		}
+
+		return nil
+	},
+}
+
+// verifyResult is the structured verification outcome of one version
+type verifyResult struct {
+	Version  string                `json:"version"`
+	Status   string                `json:"status"`
+	Problems []install.FileProblem `json:"problems,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// verifyReport is the structured output of verify
+type verifyReport struct {
+	Results []verifyResult `json:"results"`
+}
+
+var repairCmd = &cobra.Command{
+	Use:   "repair <version>",
+	Short: "Restore an installed Java version from its archive",
+	Long:  "Re-extract an installed Java version from the cached archive (downloading it again if needed) and replace the installed files.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.GetConfig()
+		if err != nil {
+			return fmt.Errorf("failed to get config: %w", err)
+		}
+
+		installer := install.NewInstaller(cfg.InstallDir)
+		matchedVersion, err := resolveInstalledUnique(installer, args[0])
+		if err != nil {
+			return err
+		}
+
+		meta, err := installer.ReadMetadata(matchedVersion)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("Java %s has no install metadata; reinstall it with 'jvt uninstall %s' and 'jvt install %s'", matchedVersion, matchedVersion, matchedVersion)
+			}
+			return err
+		}
+
+		// Uses the cached archive when present; the checksum is verified either way
+		downloader := newDownloader(cfg)
+		archivePath, err := downloader.DownloadAndVerify(cmd.Context(), meta.DownloadURL, meta.FileName, meta.Checksum)
+		if err != nil {
+			return fmt.Errorf("failed to obtain archive: %w", err)
+		}
+
+		fmt.Printf("\nRepairing Java %s...\n", matchedVersion)
+		if err := installer.Repair(cmd.Context(), archivePath, meta); err != nil {
+			return err
+		}
+
+		return emit(actionResult{Version: matchedVersion, Status: "repaired"})
+	},
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "Verify all installed Java versions")
+}
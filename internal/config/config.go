@@ -1,15 +1,83 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+// DefaultUpdateURL is the GitHub API base of the jvt repository
+const DefaultUpdateURL = "https://api.github.com/repos/rexqwer911/jvt"
+
+// DefaultRegistryCacheTTL is how long the cached Java version catalogue is used
+// before it is revalidated against the remote registry
+const DefaultRegistryCacheTTL = 24 * time.Hour
+
+// Switch policies for the default version after an upgrade
+const (
+	// SwitchIfActive switches when the replaced build was active or the default
+	SwitchIfActive = "if-active"
+	// SwitchAuto switches when the active or default version has the upgraded major
+	SwitchAuto = "auto"
+	// SwitchNever leaves the active and default versions alone
+	SwitchNever = "never"
+)
+
+// UpgradePolicy controls what `jvt upgrade` installs, keeps and switches to
+type UpgradePolicy struct {
+	// PatchOnly restricts upgrades to patch updates of the installed minor release
+	PatchOnly bool
+	// Keep is how many builds per major remain after an upgrade, the new one
+	// included. 0 removes just the replaced build.
+	Keep int
+	// Switch is one of SwitchIfActive, SwitchAuto or SwitchNever
+	Switch string
+	// AllowDistributionChange permits replacing a build with one of another distribution
+	AllowDistributionChange bool
+}
+
+// ValidSwitch reports whether s names a switch policy
+func ValidSwitch(s string) bool {
+	return s == SwitchIfActive || s == SwitchAuto || s == SwitchNever
+}
+
 // Config holds the application configuration
 type Config struct {
+	RootDir     string
 	InstallDir  string
 	DefaultJava string
 	CacheDir    string
+
+	// RegistryCacheFile stores the fetched version catalogue
+	RegistryCacheFile string
+	// RegistryCacheTTL is how long the cached catalogue is considered fresh
+	RegistryCacheTTL time.Duration
+
+	// StateFile stores the default version and other persistent choices
+	StateFile string
+	// UpdateURL is the GitHub API base of the repository jvt updates itself from
+	UpdateURL string
+
+	// Upgrade is the default upgrade policy; upgrade flags override it
+	Upgrade UpgradePolicy
+}
+
+// fileConfig mirrors the optional ~/.jvt/config.json settings file
+type fileConfig struct {
+	RegistryCacheTTL string             `json:"registry_cache_ttl,omitempty"`
+	UpdateURL        string             `json:"update_url,omitempty"`
+	Upgrade          *fileUpgradePolicy `json:"upgrade,omitempty"`
+}
+
+// fileUpgradePolicy mirrors the "upgrade" section of the settings file; unset
+// fields keep their defaults
+type fileUpgradePolicy struct {
+	PatchOnly               *bool  `json:"patch_only,omitempty"`
+	Keep                    *int   `json:"keep,omitempty"`
+	Switch                  string `json:"switch,omitempty"`
+	AllowDistributionChange *bool  `json:"allow_distribution_change,omitempty"`
 }
 
 // GetConfig returns the application configuration
@@ -20,12 +88,81 @@ func GetConfig() (*Config, error) {
 	}
 
 	jvtDir := filepath.Join(homeDir, ".jvt")
+	cacheDir := filepath.Join(jvtDir, "cache")
+
+	cfg := &Config{
+		RootDir:           jvtDir,
+		InstallDir:        filepath.Join(jvtDir, "versions"),
+		CacheDir:          cacheDir,
+		DefaultJava:       "",
+		RegistryCacheFile: filepath.Join(cacheDir, "registry.json"),
+		RegistryCacheTTL:  DefaultRegistryCacheTTL,
+		StateFile:         filepath.Join(jvtDir, "state.json"),
+		UpdateURL:         DefaultUpdateURL,
+		Upgrade:           UpgradePolicy{Switch: SwitchIfActive},
+	}
+
+	if err := cfg.loadFile(filepath.Join(jvtDir, "config.json")); err != nil {
+		return nil, err
+	}
+
+	// The environment wins over the config file, e.g. to test against a local server
+	if url := os.Getenv("JVT_UPDATE_URL"); url != "" {
+		cfg.UpdateURL = url
+	}
+
+	return cfg, nil
+}
 
-	return &Config{
-		InstallDir:  filepath.Join(jvtDir, "versions"),
-		CacheDir:    filepath.Join(jvtDir, "cache"),
-		DefaultJava: "",
-	}, nil
+// loadFile applies settings from the config file, if it exists
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if fc.RegistryCacheTTL != "" {
+		ttl, err := time.ParseDuration(fc.RegistryCacheTTL)
+		if err != nil {
+			return fmt.Errorf("invalid registry_cache_ttl in %s: %w", path, err)
+		}
+		c.RegistryCacheTTL = ttl
+	}
+
+	if fc.UpdateURL != "" {
+		c.UpdateURL = fc.UpdateURL
+	}
+
+	if u := fc.Upgrade; u != nil {
+		if u.PatchOnly != nil {
+			c.Upgrade.PatchOnly = *u.PatchOnly
+		}
+		if u.Keep != nil {
+			if *u.Keep < 0 {
+				return fmt.Errorf("invalid upgrade.keep in %s: must not be negative", path)
+			}
+			c.Upgrade.Keep = *u.Keep
+		}
+		if u.Switch != "" {
+			if !ValidSwitch(u.Switch) {
+				return fmt.Errorf("invalid upgrade.switch %q in %s (expected %s, %s or %s)", u.Switch, path, SwitchAuto, SwitchIfActive, SwitchNever)
+			}
+			c.Upgrade.Switch = u.Switch
+		}
+		if u.AllowDistributionChange != nil {
+			c.Upgrade.AllowDistributionChange = *u.AllowDistributionChange
+		}
+	}
+
+	return nil
 }
 
 // EnsureDirectories creates necessary directories if they don't exist
@@ -0,0 +1,326 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rexqwer911/jvt/internal/config"
+	"github.com/rexqwer911/jvt/internal/download"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/registry"
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// Status is the outcome of a diagnostic check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Finding is the result of one diagnostic check
+type Finding struct {
+	Check      string `json:"check"`
+	Status     Status `json:"status"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Fixable    bool   `json:"fixable"`
+	Fixed      bool   `json:"fixed"`
+
+	// fix applies the suggestion automatically; nil when it needs the user
+	fix func(ctx context.Context) error
+}
+
+// Fix applies the automatic fix of a finding
+func (f *Finding) Fix(ctx context.Context) error {
+	if f.fix == nil {
+		return fmt.Errorf("%s has no automatic fix", f.Check)
+	}
+	if err := f.fix(ctx); err != nil {
+		return err
+	}
+	f.Fixed = true
+	return nil
+}
+
+// pass creates a passing finding
+func pass(check, message string) *Finding {
+	return &Finding{Check: check, Status: StatusPass, Message: message}
+}
+
+// problem creates a warning or failure with a suggested fix. A non-nil fix
+// marks the suggestion as safe to apply automatically.
+func problem(status Status, check, message, suggestion string, fix func(ctx context.Context) error) *Finding {
+	return &Finding{
+		Check:      check,
+		Status:     status,
+		Message:    message,
+		Suggestion: suggestion,
+		Fixable:    fix != nil,
+		fix:        fix,
+	}
+}
+
+// Doctor runs environment diagnostics for a jvt installation
+type Doctor struct {
+	cfg        *config.Config
+	installer  *install.Installer
+	mgr        *version.Manager
+	downloader *download.Downloader
+}
+
+// New creates a doctor for the given configuration
+func New(cfg *config.Config) *Doctor {
+	return &Doctor{
+		cfg:        cfg,
+		installer:  install.NewInstaller(cfg.InstallDir),
+		mgr:        version.NewManager(cfg.InstallDir, cfg.StateFile),
+		downloader: download.NewDownloader(cfg.CacheDir),
+	}
+}
+
+// Run executes all checks in a fixed order
+func (d *Doctor) Run() []*Finding {
+	checks := []func() *Finding{
+		d.checkJavaOnPath,
+		d.checkJavaHome,
+	}
+	checks = append(checks, d.platformChecks()...)
+	checks = append(checks,
+		d.checkVersionDirs,
+		d.checkRegistryCache,
+		d.checkDownloadCache,
+	)
+
+	findings := make([]*Finding, 0, len(checks))
+	for _, check := range checks {
+		if f := check(); f != nil {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// checkJavaOnPath makes sure the first java on PATH is the jvt-managed one
+func (d *Doctor) checkJavaOnPath() *Finding {
+	const check = "java-on-path"
+
+	javas := findOnPath("java")
+	if len(javas) == 0 {
+		return problem(StatusWarn, check, "no java executable found on PATH",
+			"Run 'jvt use <version>' and open a new terminal", nil)
+	}
+
+	current, err := d.mgr.GetCurrentVersion()
+	if err != nil {
+		if d.isManaged(javas[0]) {
+			return pass(check, fmt.Sprintf("java resolves to %s", javas[0]))
+		}
+		return problem(StatusWarn, check,
+			fmt.Sprintf("java resolves to %s, which is not managed by jvt", javas[0]),
+			"Run 'jvt use <version>' and open a new terminal", nil)
+	}
+
+	expected := filepath.Join(d.installer.GetJavaHome(current), "bin")
+	for idx, java := range javas {
+		if !sameDir(filepath.Dir(java), expected) {
+			continue
+		}
+		if idx == 0 {
+			return pass(check, fmt.Sprintf("java resolves to Java %s", current))
+		}
+		return problem(StatusFail, check,
+			fmt.Sprintf("%s comes before Java %s on PATH", strings.Join(javas[:idx], ", "), current),
+			pathSuggestion, nil)
+	}
+
+	return problem(StatusFail, check,
+		fmt.Sprintf("JAVA_HOME is Java %s but %s is not on PATH", current, expected),
+		pathSuggestion, nil)
+}
+
+// checkJavaHome compares JAVA_HOME with the recorded default version
+func (d *Doctor) checkJavaHome() *Finding {
+	const check = "java-home"
+
+	def, err := d.mgr.GetDefaultVersion()
+	if err != nil {
+		return problem(StatusFail, check, fmt.Sprintf("cannot read jvt state: %v", err),
+			"Run 'jvt use <version>' to record a default version", nil)
+	}
+
+	current, currentErr := d.mgr.GetCurrentVersion()
+
+	switch {
+	case def == "" && currentErr != nil:
+		return problem(StatusWarn, check, "no default Java version recorded",
+			"Run 'jvt use <version>' to choose one", nil)
+	case def == "":
+		return problem(StatusWarn, check, fmt.Sprintf("JAVA_HOME is Java %s but no default version is recorded", current),
+			fmt.Sprintf("Run 'jvt use %s' to record it", current), nil)
+	case !d.installer.IsInstalled(def):
+		return problem(StatusFail, check, fmt.Sprintf("the default version %s is not installed", def),
+			"Run 'jvt use <version>' to choose another default", nil)
+	case currentErr != nil:
+		return problem(StatusWarn, check, fmt.Sprintf("JAVA_HOME does not point at the default version %s (%v)", def, currentErr),
+			"Open a new terminal so the shell picks up the default version", nil)
+	case current != def:
+		return problem(StatusWarn, check, fmt.Sprintf("JAVA_HOME is Java %s but the default is %s", current, def),
+			fmt.Sprintf("Open a new terminal, or run 'jvt use %s' to make it the default", current), nil)
+	default:
+		return pass(check, fmt.Sprintf("JAVA_HOME matches the default version %s", def))
+	}
+}
+
+// checkVersionDirs finds version directories that no longer contain a JDK
+func (d *Doctor) checkVersionDirs() *Finding {
+	const check = "version-dirs"
+
+	versions, err := d.installer.ListInstalled()
+	if err != nil {
+		return problem(StatusFail, check, err.Error(), "Check the permissions of "+d.cfg.InstallDir, nil)
+	}
+
+	var dangling []string
+	for _, v := range versions {
+		if err := d.installer.CheckLayout(v); err != nil {
+			dangling = append(dangling, v)
+		}
+	}
+
+	if len(dangling) == 0 {
+		return pass(check, fmt.Sprintf("%d installed version(s) look complete", len(versions)))
+	}
+
+	// Removing a version tree is not a safe fix: the version may be pinned, the
+	// default or required by a project, which only uninstall checks
+	return problem(StatusFail, check,
+		fmt.Sprintf("version directories without a JDK: %s", strings.Join(dangling, ", ")),
+		"Restore them with 'jvt repair <version>', or remove them with 'jvt uninstall <version>'",
+		nil)
+}
+
+// checkRegistryCache makes sure the cached version catalogue can be read
+func (d *Doctor) checkRegistryCache() *Finding {
+	const check = "registry-cache"
+
+	if err := registry.CheckCache(d.cfg.RegistryCacheFile); err != nil {
+		return problem(StatusFail, check, fmt.Sprintf("%s is corrupt: %v", d.cfg.RegistryCacheFile, err),
+			"Delete it; it is fetched again on the next run",
+			func(context.Context) error {
+				return os.Remove(d.cfg.RegistryCacheFile)
+			})
+	}
+	return pass(check, "version catalogue cache is readable")
+}
+
+// checkDownloadCache finds interrupted downloads and cached archives that no
+// longer match the checksum recorded when they were installed
+func (d *Doctor) checkDownloadCache() *Finding {
+	const check = "download-cache"
+
+	partials, err := d.downloader.RemoveStalePartials(true)
+	if err != nil {
+		return problem(StatusFail, check, err.Error(), "Check the permissions of "+d.cfg.CacheDir, nil)
+	}
+
+	var corrupt []string
+	versions, _ := d.installer.ListInstalled()
+	for _, v := range versions {
+		meta, err := d.installer.ReadMetadata(v)
+		if err != nil || meta.Checksum == "" {
+			continue
+		}
+
+		archive, err := d.downloader.CachePath(meta.FileName)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(archive); err != nil {
+			continue
+		}
+
+		var checksumErr *download.ChecksumError
+		if err := d.downloader.VerifyChecksum(archive, meta.Checksum); errors.As(err, &checksumErr) {
+			corrupt = append(corrupt, archive)
+		}
+	}
+
+	if len(partials) == 0 && len(corrupt) == 0 {
+		return pass(check, "download cache is consistent")
+	}
+
+	fix := func(context.Context) error {
+		if _, err := d.downloader.RemoveStalePartials(false); err != nil {
+			return err
+		}
+		for _, archive := range corrupt {
+			if err := os.Remove(archive); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var issues []string
+	status := StatusWarn
+	if len(corrupt) > 0 {
+		issues = append(issues, fmt.Sprintf("cached archives with a wrong checksum: %s", strings.Join(corrupt, ", ")))
+		status = StatusFail
+	}
+	if len(partials) > 0 {
+		issues = append(issues, fmt.Sprintf("interrupted downloads: %s", strings.Join(partials, ", ")))
+	}
+
+	return problem(status, check, strings.Join(issues, "; "),
+		"Delete them; archives are downloaded again when needed", fix)
+}
+
+// isManaged reports whether a path lies inside the jvt install directory
+func (d *Doctor) isManaged(path string) bool {
+	rel, err := filepath.Rel(d.cfg.InstallDir, path)
+	return err == nil && !strings.HasPrefix(rel, "..")
+}
+
+// findOnPath returns every executable called name on PATH, in PATH order
+func findOnPath(name string) []string {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	var found []string
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || seen[filepath.Clean(dir)] {
+			continue
+		}
+		seen[filepath.Clean(dir)] = true
+
+		candidate := filepath.Join(dir, name)
+		fi, err := os.Stat(candidate)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		if runtime.GOOS != "windows" && fi.Mode()&0111 == 0 {
+			continue
+		}
+		found = append(found, candidate)
+	}
+	return found
+}
+
+// sameDir compares two directory paths, case-insensitively on Windows
+func sameDir(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,130 @@
+//go:build linux || darwin
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// pathSuggestion explains how to put the jvt-managed java first on PATH
+const pathSuggestion = "Make sure ~/.jvt/jvt.sh is sourced after any other PATH changes in your shell startup file, then open a new terminal"
+
+// platformChecks returns the checks specific to Unix shells
+func (d *Doctor) platformChecks() []func() *Finding {
+	return []func() *Finding{
+		d.checkShellIntegration,
+		d.checkActivationScript,
+	}
+}
+
+// checkShellIntegration looks for a missing, duplicated or modified jvt block
+// in the shell startup files
+func (d *Doctor) checkShellIntegration() *Finding {
+	const check = "shell-integration"
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return problem(StatusFail, check, err.Error(), "Set HOME", nil)
+	}
+
+	var configured, duplicated, modified []string
+	for _, rcPath := range version.ShellRCFiles(home) {
+		content, err := os.ReadFile(rcPath)
+		if err != nil {
+			continue
+		}
+		switch {
+		case !version.HasShellIntegration(string(content)):
+		case version.ShellIntegrationModified(string(content)):
+			modified = append(modified, rcPath)
+		case version.ShellIntegrationCount(string(content)) > 1:
+			duplicated = append(duplicated, rcPath)
+		default:
+			configured = append(configured, rcPath)
+		}
+	}
+
+	// Edited blocks are the user's; adding or removing blocks around them
+	// would never settle
+	if len(modified) > 0 {
+		return problem(StatusWarn, check,
+			fmt.Sprintf("jvt integration block differs from the one jvt writes in %s", strings.Join(modified, ", ")),
+			"Check that it still sources ~/.jvt/jvt.sh and defines the jvt function, or delete it and run 'jvt doctor --fix'",
+			nil)
+	}
+
+	if len(duplicated) > 0 {
+		return problem(StatusWarn, check,
+			fmt.Sprintf("jvt integration block appears more than once in %s", strings.Join(duplicated, ", ")),
+			"Remove the extra copies",
+			func(context.Context) error {
+				for _, rcPath := range duplicated {
+					if err := version.RemoveDuplicateShellIntegration(rcPath); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	}
+
+	if len(configured) == 0 {
+		rcPath := defaultRCFile(home)
+		return problem(StatusFail, check, "no shell startup file sources ~/.jvt/jvt.sh",
+			fmt.Sprintf("Add the jvt integration block to %s", rcPath),
+			func(context.Context) error {
+				return version.AddShellIntegration(rcPath)
+			})
+	}
+
+	return pass(check, fmt.Sprintf("configured in %s", strings.Join(configured, ", ")))
+}
+
+// checkActivationScript makes sure jvt.sh exports the default version
+func (d *Doctor) checkActivationScript() *Finding {
+	const check = "jvt-sh"
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return problem(StatusFail, check, err.Error(), "Set HOME", nil)
+	}
+
+	def, _ := d.mgr.GetDefaultVersion()
+	if def == "" || !d.installer.IsInstalled(def) {
+		// Nothing to compare against; java-home reports the missing default
+		return nil
+	}
+
+	refresh := d.mgr.RefreshActivationScript
+	scriptPath := version.ActivationScriptPath(home)
+	expected := d.installer.GetJavaHome(def)
+
+	javaHome, err := version.ActivationScriptJavaHome(home)
+	switch {
+	case os.IsNotExist(err):
+		return problem(StatusFail, check, fmt.Sprintf("%s is missing", scriptPath),
+			fmt.Sprintf("Recreate it for the default version %s", def), refresh)
+	case err != nil:
+		return problem(StatusFail, check, err.Error(),
+			fmt.Sprintf("Recreate it for the default version %s", def), refresh)
+	case filepath.Clean(javaHome) != filepath.Clean(expected):
+		return problem(StatusFail, check,
+			fmt.Sprintf("%s exports %s but the default version is %s", scriptPath, javaHome, def),
+			fmt.Sprintf("Rewrite it for Java %s", def), refresh)
+	default:
+		return pass(check, fmt.Sprintf("%s exports Java %s", scriptPath, def))
+	}
+}
+
+// defaultRCFile picks the startup file of the user's login shell
+func defaultRCFile(home string) string {
+	if strings.HasSuffix(os.Getenv("SHELL"), "zsh") {
+		return filepath.Join(home, ".zshrc")
+	}
+	return filepath.Join(home, ".bashrc")
+}
@@ -0,0 +1,92 @@
+//go:build linux || darwin
+
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// writtenBlock returns the integration block jvt writes into a new file
+func writtenBlock(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rc")
+	if err := version.AddShellIntegration(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestCheckShellIntegrationConverges(t *testing.T) {
+	integrationBlock := writtenBlock(t)
+
+	tests := []struct {
+		name       string
+		bashrc     string
+		wantStatus Status
+		wantFix    bool
+		wantBlocks int
+	}{
+		{name: "intact block", bashrc: "alias ll='ls -l'\n" + integrationBlock, wantStatus: StatusPass, wantBlocks: 1},
+		{name: "missing block", bashrc: "alias ll='ls -l'\n", wantStatus: StatusFail, wantFix: true, wantBlocks: 1},
+		{name: "duplicated block", bashrc: integrationBlock + integrationBlock, wantStatus: StatusWarn, wantFix: true, wantBlocks: 1},
+		{
+			name:       "block without the source line",
+			bashrc:     "jvt() {\n    command jvt \"$@\"\n}\n",
+			wantStatus: StatusWarn,
+			wantBlocks: 1,
+		},
+		{
+			name:       "edited block and a copy",
+			bashrc:     strings.Replace(integrationBlock, "command jvt", "/opt/jvt/jvt", 1) + integrationBlock,
+			wantStatus: StatusWarn,
+			wantBlocks: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			bashrc := filepath.Join(home, ".bashrc")
+			if err := os.WriteFile(bashrc, []byte(tt.bashrc), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			d := &Doctor{}
+			f := d.checkShellIntegration()
+			if f.Status != tt.wantStatus || f.Fixable != tt.wantFix {
+				t.Fatalf("got %s (fixable %v): %s; want %s (fixable %v)", f.Status, f.Fixable, f.Message, tt.wantStatus, tt.wantFix)
+			}
+
+			// Applying the fixes repeatedly must settle without piling up blocks
+			for i := 0; i < 3 && f.Fixable; i++ {
+				if err := f.Fix(context.Background()); err != nil {
+					t.Fatalf("Fix() error = %v", err)
+				}
+				f = d.checkShellIntegration()
+			}
+			if f.Fixable {
+				t.Errorf("still fixable after repeated fixes: %s", f.Message)
+			}
+
+			content, err := os.ReadFile(bashrc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Count(string(content), "jvt() {"); got != tt.wantBlocks {
+				t.Errorf(".bashrc has %d blocks, want %d", got, tt.wantBlocks)
+			}
+		})
+	}
+}
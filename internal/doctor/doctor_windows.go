@@ -0,0 +1,66 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// pathSuggestion explains how to put the jvt-managed java first on PATH
+const pathSuggestion = "Remove the other Java entries from PATH in System Properties > Environment Variables, then open a new terminal"
+
+// platformChecks returns the checks specific to the Windows environment
+func (d *Doctor) platformChecks() []func() *Finding {
+	return []func() *Finding{
+		d.checkUserEnvironment,
+		d.checkSystemJava,
+	}
+}
+
+// checkUserEnvironment makes sure the user JAVA_HOME points at the default version
+func (d *Doctor) checkUserEnvironment() *Finding {
+	const check = "user-environment"
+
+	def, _ := d.mgr.GetDefaultVersion()
+	if def == "" || !d.installer.IsInstalled(def) {
+		// Nothing to compare against; java-home reports the missing default
+		return nil
+	}
+
+	javaHome, err := version.UserJavaHome()
+	if err != nil {
+		return problem(StatusFail, check, err.Error(), fmt.Sprintf("Run 'jvt use %s'", def), nil)
+	}
+
+	expected := d.installer.GetJavaHome(def)
+	if !strings.EqualFold(filepath.Clean(javaHome), filepath.Clean(expected)) {
+		return problem(StatusFail, check,
+			fmt.Sprintf("the user JAVA_HOME is %q but the default version is %s", javaHome, def),
+			fmt.Sprintf("Point the user environment at Java %s", def),
+			d.mgr.RefreshUserEnvironment)
+	}
+
+	return pass(check, fmt.Sprintf("the user environment points at Java %s", def))
+}
+
+// checkSystemJava reports Java installations on the SYSTEM PATH, which take
+// precedence over the user PATH
+func (d *Doctor) checkSystemJava() *Finding {
+	const check = "system-java"
+
+	paths, err := version.SystemJavaPaths()
+	if err != nil {
+		return problem(StatusWarn, check, fmt.Sprintf("cannot read the system PATH: %v", err), "", nil)
+	}
+
+	if len(paths) > 0 {
+		return problem(StatusWarn, check,
+			fmt.Sprintf("Java entries on the SYSTEM PATH may override jvt: %s", strings.Join(paths, "; ")),
+			"Remove them from the system PATH (requires Administrator), or run 'jvt use <version>' from an elevated terminal",
+			nil)
+	}
+
+	return pass(check, "no Java on the system PATH")
+}
@@ -1,6 +1,7 @@
 package download
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -8,13 +9,16 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/rexqwer911/jvt/internal/lock"
 	"github.com/schollz/progressbar/v3"
 )
 
 // Downloader handles downloading files with progress tracking
 type Downloader struct {
 	cacheDir string
+	offline  bool
 }
 
 // NewDownloader creates a new downloader instance
@@ -24,58 +28,181 @@ func NewDownloader(cacheDir string) *Downloader {
 	}
 }
 
+// SetOffline restricts the downloader to files already in the cache
+func (d *Downloader) SetOffline(offline bool) {
+	d.offline = offline
+}
+
+// Progress follows a download: SetTotal receives the size from the server,
+// and the body is written to it as it arrives
+type Progress interface {
+	io.Writer
+	SetTotal(total int64)
+}
+
 // Download downloads a file from URL to the cache directory
-func (d *Downloader) Download(url, filename string, showProgress bool) (string, error) {
+func (d *Downloader) Download(ctx context.Context, url, filename string, showProgress bool) (string, error) {
+	var progress func(size int64) io.Writer
+	if showProgress {
+		progress = func(size int64) io.Writer {
+			return progressbar.DefaultBytes(size, fmt.Sprintf("Downloading %s", filename))
+		}
+	}
+	return d.download(ctx, url, filename, progress, true)
+}
+
+// DownloadWithProgress downloads a file like Download, reporting to progress
+// instead of printing. A file already in the cache is reported with its size.
+func (d *Downloader) DownloadWithProgress(ctx context.Context, url, filename string, progress Progress) (string, error) {
+	return d.download(ctx, url, filename, func(size int64) io.Writer {
+		progress.SetTotal(size)
+		return progress
+	}, false)
+}
+
+// download fetches url into the cache unless it is there already; progress,
+// if not nil, returns the writer that follows the transfer of size bytes
+func (d *Downloader) download(ctx context.Context, url, filename string, progress func(size int64) io.Writer, announceCached bool) (string, error) {
 	// Ensure cache directory exists
 	if err := os.MkdirAll(d.cacheDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	destPath := filepath.Join(d.cacheDir, filename)
+	destPath, err := d.CachePath(filename)
+	if err != nil {
+		return "", err
+	}
+
+	// Keep other jvt processes from downloading the same file concurrently
+	l, err := lock.Acquire(ctx, destPath+".lock", filename, lock.DefaultTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer l.Release()
 
 	// Check if file already exists
-	if _, err := os.Stat(destPath); err == nil {
-		fmt.Printf("File already exists in cache: %s\n", filename)
+	if info, err := os.Stat(destPath); err == nil {
+		if announceCached {
+			fmt.Printf("File already exists in cache: %s\n", filename)
+		} else if progress != nil {
+			progress(info.Size())
+		}
 		return destPath, nil
 	}
 
-	// Create HTTP request
-	resp, err := http.Get(url)
+	if d.offline {
+		return "", &NetworkError{URL: url, Err: fmt.Errorf("%s is not in the download cache and --offline is set", filename)}
+	}
+
+	// Create HTTP request; cancelling ctx aborts the transfer
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to download: %w", err)
 	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", &NetworkError{URL: url, Err: err}
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("download failed with status: %d", resp.StatusCode)
+		return "", &NetworkError{URL: url, Err: fmt.Errorf("server returned status %d", resp.StatusCode)}
 	}
+	body := &bodyReader{url: url, r: resp.Body}
 
-	// Create destination file
-	out, err := os.Create(destPath)
+	// Download to a temporary name so an interrupted download never looks cached
+	partPath := destPath + ".part"
+	out, err := os.Create(partPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to create file: %w", err)
 	}
 	defer out.Close()
 
 	// Download with progress bar
-	if showProgress {
-		bar := progressbar.DefaultBytes(
-			resp.ContentLength,
-			fmt.Sprintf("Downloading %s", filename),
-		)
-		_, err = io.Copy(io.MultiWriter(out, bar), resp.Body)
+	if progress != nil {
+		_, err = io.Copy(io.MultiWriter(out, progress(resp.ContentLength)), body)
 	} else {
-		_, err = io.Copy(out, resp.Body)
+		_, err = io.Copy(out, body)
 	}
 
+	if err == nil {
+		err = out.Close()
+	}
 	if err != nil {
-		os.Remove(destPath) // Clean up partial download
+		os.Remove(partPath) // Clean up partial download
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+
+	if err := os.Rename(partPath, destPath); err != nil {
+		os.Remove(partPath)
 		return "", fmt.Errorf("failed to save file: %w", err)
 	}
 
 	return destPath, nil
 }
 
+// CachePath returns where a file is stored in the download cache. Names that
+// would resolve outside the cache directory are rejected.
+func (d *Downloader) CachePath(filename string) (string, error) {
+	if err := ValidateFileName(filename); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(d.cacheDir, filename)
+	rel, err := filepath.Rel(d.cacheDir, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file name %q: it resolves outside the download cache", filename)
+	}
+	return path, nil
+}
+
+// ValidateFileName returns an error unless name is a plain file name that
+// stays in the directory it is joined onto, with no separators or ".."
+func ValidateFileName(name string) error {
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid file name %q: it must not contain a path", name)
+	}
+	return nil
+}
+
+// RemoveStalePartials deletes partial downloads left behind by interrupted
+// jvt processes and returns their names. Files still being downloaded by
+// another process are left alone.
+func (d *Downloader) RemoveStalePartials(dryRun bool) ([]string, error) {
+	entries, err := os.ReadDir(d.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".part") {
+			continue
+		}
+
+		partPath := filepath.Join(d.cacheDir, entry.Name())
+		l, err := lock.TryAcquire(strings.TrimSuffix(partPath, ".part") + ".lock")
+		if err != nil {
+			continue // In use, or the lock can't be checked
+		}
+
+		if !dryRun {
+			err = os.Remove(partPath)
+		}
+		l.Release()
+		if err != nil {
+			return stale, fmt.Errorf("failed to remove %s: %w", entry.Name(), err)
+		}
+		stale = append(stale, entry.Name())
+	}
+
+	return stale, nil
+}
+
 // VerifyChecksum verifies the SHA256 checksum of a file
 func (d *Downloader) VerifyChecksum(filePath, expectedChecksum string) error {
 	file, err := os.Open(filePath)
@@ -92,15 +219,15 @@ func (d *Downloader) VerifyChecksum(filePath, expectedChecksum string) error {
 	actualChecksum := hex.EncodeToString(hash.Sum(nil))
 
 	if actualChecksum != expectedChecksum {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
+		return &ChecksumError{Path: filePath, Expected: expectedChecksum, Actual: actualChecksum}
 	}
 
 	return nil
 }
 
 // DownloadAndVerify downloads a file and verifies its checksum
-func (d *Downloader) DownloadAndVerify(url, filename, checksum string) (string, error) {
-	filePath, err := d.Download(url, filename, true)
+func (d *Downloader) DownloadAndVerify(ctx context.Context, url, filename, checksum string) (string, error) {
+	filePath, err := d.Download(ctx, url, filename, true)
 	if err != nil {
 		return "", err
 	}
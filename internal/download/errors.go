@@ -0,0 +1,47 @@
+package download
+
+import (
+	"fmt"
+	"io"
+)
+
+// ChecksumError is returned when a downloaded file does not match its expected SHA256
+type ChecksumError struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// NetworkError is returned when a file cannot be downloaded, either because the
+// request failed, the server answered with an error status or the transfer broke off
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("cannot download %s: %v", e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// bodyReader marks read errors of a response body as network errors, so they
+// can be told apart from failures writing the file
+type bodyReader struct {
+	url string
+	r   io.Reader
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = &NetworkError{URL: b.url, Err: err}
+	}
+	return n, err
+}
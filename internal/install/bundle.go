@@ -0,0 +1,53 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// bundleLinkPath returns the ~/Library/Java/JavaVirtualMachines entry for a version
+func bundleLinkPath(version string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Java", "JavaVirtualMachines", "jvt-"+version+".jdk"), nil
+}
+
+// registerBundle links a macOS bundle-style install into ~/Library/Java/JavaVirtualMachines
+// so that /usr/libexec/java_home can discover it. It is a no-op on other platforms.
+func registerBundle(versionDir, version string) error {
+	if runtime.GOOS != "darwin" {
+		return nil
+	}
+
+	linkPath, err := bundleLinkPath(version)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(linkPath), 0755); err != nil {
+		return err
+	}
+
+	os.Remove(linkPath) // Replace a stale link from a previous install
+	return os.Symlink(versionDir, linkPath)
+}
+
+// unregisterBundle removes the link created by registerBundle, if any
+func unregisterBundle(version string) {
+	if runtime.GOOS != "darwin" {
+		return
+	}
+
+	linkPath, err := bundleLinkPath(version)
+	if err != nil {
+		return
+	}
+
+	// Only remove symlinks; never touch a real JDK bundle
+	if fi, err := os.Lstat(linkPath); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		os.Remove(linkPath)
+	}
+}
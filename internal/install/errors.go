@@ -0,0 +1,21 @@
+package install
+
+import "fmt"
+
+// AlreadyInstalledError is returned when installing a version that is already present
+type AlreadyInstalledError struct {
+	Version string
+}
+
+func (e *AlreadyInstalledError) Error() string {
+	return fmt.Sprintf("version %s is already installed", e.Version)
+}
+
+// NotInstalledError is returned when operating on a version that is not installed
+type NotInstalledError struct {
+	Version string
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("version %s is not installed", e.Version)
+}
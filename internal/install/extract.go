@@ -0,0 +1,524 @@
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bodgit/sevenzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// archiveFormat identifies a supported archive container and compression
+type archiveFormat string
+
+const (
+	formatZip    archiveFormat = "zip"
+	formatTarGz  archiveFormat = "tar.gz"
+	formatTarXz  archiveFormat = "tar.xz"
+	formatTarZst archiveFormat = "tar.zst"
+	format7z     archiveFormat = "7z"
+)
+
+// extractor unpacks an archive into destDir, stripping the archive's root directory
+type extractor func(i *Installer, archivePath, destDir string) error
+
+// extractors maps each supported archive format to its extractor
+var extractors = map[archiveFormat]extractor{
+	formatZip:    (*Installer).extractZip,
+	formatTarGz:  (*Installer).extractTarGz,
+	formatTarXz:  (*Installer).extractTarXz,
+	formatTarZst: (*Installer).extractTarZst,
+	format7z:     (*Installer).extract7z,
+}
+
+// archiveSignatures lists the magic bytes each format starts with. Compressed
+// streams (gzip, xz, zstd) are assumed to wrap a tar archive, which covers
+// .tgz as well as .tar.gz.
+var archiveSignatures = []struct {
+	format archiveFormat
+	magic  []byte
+}{
+	{formatZip, []byte("PK\x03\x04")},
+	{formatTarGz, []byte{0x1f, 0x8b}},
+	{formatTarXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{formatTarZst, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{format7z, []byte{'7', 'z', 0xbc, 0xaf, 0x27, 0x1c}},
+}
+
+// detectArchiveFormat identifies an archive by its leading bytes instead of its file name
+func detectArchiveFormat(archivePath string) (archiveFormat, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 8)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read archive header: %w", err)
+	}
+	header = header[:n]
+
+	for _, sig := range archiveSignatures {
+		if bytes.HasPrefix(header, sig.magic) {
+			return sig.format, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+}
+
+// extractZip extracts a zip archive to the destination directory
+func (i *Installer) extractZip(archivePath, destDir string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// Find the root directory in the archive (usually jdk-x.x.x+x)
+	var rootDir string
+	if len(r.File) > 0 {
+		firstFile := r.File[0].Name
+		parts := strings.Split(firstFile, "/")
+		if len(parts) > 0 {
+			rootDir = parts[0]
+		}
+	}
+
+	var dirTimes []entryTime
+	var links []pendingLink
+
+	for _, f := range r.File {
+		// Skip the root directory itself
+		relativePath := stripRoot(f.Name, rootDir)
+		if relativePath == "" {
+			continue
+		}
+
+		fpath, err := safeJoin(destDir, relativePath)
+		if err != nil {
+			return err
+		}
+
+		mode := f.Mode()
+
+		switch {
+		case mode.IsDir():
+			if err := mkdirInside(destDir, fpath); err != nil {
+				return err
+			}
+			dirTimes = append(dirTimes, entryTime{fpath, f.Modified})
+
+		case mode&os.ModeSymlink != 0:
+			// Zip stores the link target as the file content
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			target, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			links = append(links, pendingLink{fpath, string(target)})
+
+		default:
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = writeFile(destDir, fpath, rc, mode, f.Modified)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := createSymlinks(destDir, links); err != nil {
+		return err
+	}
+	return restoreDirTimes(dirTimes)
+}
+
+// extract7z extracts a 7z archive to the destination directory
+func (i *Installer) extract7z(archivePath, destDir string) error {
+	r, err := sevenzip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// Find the root directory in the archive, as for zip
+	var rootDir string
+	if len(r.File) > 0 {
+		rootDir = strings.Split(r.File[0].Name, "/")[0]
+	}
+
+	var dirTimes []entryTime
+	var links []pendingLink
+
+	for _, f := range r.File {
+		relativePath := stripRoot(f.Name, rootDir)
+		if relativePath == "" {
+			continue
+		}
+
+		fpath, err := safeJoin(destDir, relativePath)
+		if err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := mkdirInside(destDir, fpath); err != nil {
+				return err
+			}
+			dirTimes = append(dirTimes, entryTime{fpath, f.Modified})
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		// Like zip, 7z stores the link target as the file content
+		if f.Mode()&os.ModeSymlink != 0 {
+			target, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+			links = append(links, pendingLink{fpath, string(target)})
+			continue
+		}
+
+		err = writeFile(destDir, fpath, rc, f.Mode(), f.Modified)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := createSymlinks(destDir, links); err != nil {
+		return err
+	}
+	return restoreDirTimes(dirTimes)
+}
+
+// extractTarGz extracts a tar.gz archive to the destination directory
+func (i *Installer) extractTarGz(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	uncompressedStream, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer uncompressedStream.Close()
+
+	return extractTar(uncompressedStream, destDir)
+}
+
+// extractTarXz extracts a tar.xz archive to the destination directory
+func (i *Installer) extractTarXz(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	uncompressedStream, err := xz.NewReader(file)
+	if err != nil {
+		return err
+	}
+
+	return extractTar(uncompressedStream, destDir)
+}
+
+// extractTarZst extracts a tar.zst archive to the destination directory
+func (i *Installer) extractTarZst(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	uncompressedStream, err := zstd.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer uncompressedStream.Close()
+
+	return extractTar(uncompressedStream, destDir)
+}
+
+// extractTar extracts an uncompressed tar stream to the destination directory
+func extractTar(r io.Reader, destDir string) error {
+	tarReader := tar.NewReader(r)
+
+	// Find the root directory
+	// In tar, we invoke Next() to get headers. We can't easily peek first like in zip.
+	// So we'll have to detect rootDir on the fly or assuming the first entry determines it if it's a dir.
+	// However, tar ordering isn't guaranteed to be depth-first strictly, but usually is.
+
+	// A simpler approach for stripping root component:
+	// Determine root component from the first entry.
+	var rootDir string
+	var dirTimes []entryTime
+	var links []pendingLink
+
+	for {
+		header, err := tarReader.Next()
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if rootDir == "" {
+			parts := strings.Split(header.Name, "/")
+			if len(parts) > 0 {
+				rootDir = parts[0]
+			}
+		}
+
+		relativePath := stripRoot(header.Name, rootDir)
+		if relativePath == "" {
+			continue
+		}
+
+		fpath, err := safeJoin(destDir, relativePath)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirInside(destDir, fpath); err != nil {
+				return err
+			}
+			dirTimes = append(dirTimes, entryTime{fpath, header.ModTime})
+		case tar.TypeReg:
+			if err := writeFile(destDir, fpath, tarReader, header.FileInfo().Mode(), header.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			links = append(links, pendingLink{fpath, header.Linkname})
+		case tar.TypeLink:
+			// Hardlink targets are archive paths, so they need the same root stripping
+			targetPath, err := safeJoin(destDir, stripRoot(header.Linkname, rootDir))
+			if err != nil {
+				return fmt.Errorf("illegal hardlink %s -> %s", header.Name, header.Linkname)
+			}
+			if real, err := filepath.EvalSymlinks(targetPath); err != nil || !insideDir(destDir, real) {
+				return fmt.Errorf("illegal hardlink %s -> %s: target outside install directory", header.Name, header.Linkname)
+			}
+			if err := mkdirInside(destDir, filepath.Dir(fpath)); err != nil {
+				return err
+			}
+			os.Remove(fpath)
+			if err := os.Link(targetPath, fpath); err != nil {
+				return err
+			}
+		}
+		// Other entry types (devices, FIFOs) never appear in JDK archives and are skipped
+	}
+
+	if err := createSymlinks(destDir, links); err != nil {
+		return err
+	}
+	return restoreDirTimes(dirTimes)
+}
+
+// pendingLink is a symlink entry, created only after every other entry has
+// been written so that no file is ever written through a link from the archive
+type pendingLink struct {
+	path   string
+	target string
+}
+
+// entryTime records the modification time to restore on an extracted directory
+type entryTime struct {
+	path    string
+	modTime time.Time
+}
+
+// stripRoot removes the archive's root directory from an entry name
+func stripRoot(name, rootDir string) string {
+	if rootDir != "" && strings.HasPrefix(name, rootDir+"/") {
+		return strings.TrimPrefix(name, rootDir+"/")
+	}
+	if name == rootDir || name == rootDir+"/" {
+		return ""
+	}
+	return name
+}
+
+// safeJoin joins an archive path onto destDir, rejecting paths that escape it (ZipSlip)
+func safeJoin(destDir, relativePath string) (string, error) {
+	fpath := filepath.Join(destDir, relativePath)
+	if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path: %s", fpath)
+	}
+	return fpath, nil
+}
+
+// sanitizeMode drops setuid/setgid/sticky bits and group/other write permission,
+// matching what extracting with a conventional 022 umask would produce
+func sanitizeMode(mode os.FileMode) os.FileMode {
+	return mode.Perm() &^ 0022
+}
+
+// insideDir reports whether path, which has its symlinks resolved, is dir or
+// lies below it once dir's own symlinks are resolved too
+func insideDir(dir, path string) bool {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(realDir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// mkdirInside creates dir and its parents after checking that the deepest
+// existing ancestor, with symlinks resolved, lies inside destDir. The
+// directories created below it are new and can't be links.
+func mkdirInside(destDir, dir string) error {
+	existing := dir
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil || !insideDir(destDir, real) {
+		return fmt.Errorf("illegal file path: %s resolves outside the install directory", dir)
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// writeFile writes a regular file, replacing any existing entry, and restores its mtime
+func writeFile(destDir, fpath string, r io.Reader, mode os.FileMode, modTime time.Time) error {
+	if err := mkdirInside(destDir, filepath.Dir(fpath)); err != nil {
+		return err
+	}
+
+	// Remove whatever is there first, so that O_EXCL never follows a link
+	if err := os.Remove(fpath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	perm := sanitizeMode(mode)
+	outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(outFile, r); err != nil {
+		outFile.Close()
+		return err
+	}
+	if err := outFile.Close(); err != nil {
+		return err
+	}
+
+	// OpenFile only applies the mode to new files, and the process umask may differ
+	if err := os.Chmod(fpath, perm); err != nil {
+		return err
+	}
+
+	if !modTime.IsZero() {
+		return os.Chtimes(fpath, modTime, modTime)
+	}
+	return nil
+}
+
+// createSymlinks creates the symlinks of an archive once all other entries
+// are in place, then checks that none of them resolves outside destDir, which
+// a chain of individually harmless links could otherwise achieve
+func createSymlinks(destDir string, links []pendingLink) error {
+	for _, link := range links {
+		if err := createSymlink(destDir, link.path, link.target); err != nil {
+			return err
+		}
+	}
+
+	for _, link := range links {
+		real, err := filepath.EvalSymlinks(link.path)
+		if err != nil {
+			continue // Dangling links point at nothing that could be written through
+		}
+		if !insideDir(destDir, real) {
+			return fmt.Errorf("illegal symlink %s -> %s: target outside install directory", link.path, link.target)
+		}
+	}
+	return nil
+}
+
+// createSymlink creates a symlink after checking that its target, resolved
+// from the real location of the link's directory, stays inside destDir
+func createSymlink(destDir, fpath, target string) error {
+	if filepath.IsAbs(target) || strings.HasPrefix(target, "/") {
+		return fmt.Errorf("illegal symlink %s -> %s: absolute target", fpath, target)
+	}
+
+	parent := filepath.Dir(fpath)
+	if err := mkdirInside(destDir, parent); err != nil {
+		return err
+	}
+
+	realParent, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return err
+	}
+	resolved := filepath.Join(realParent, filepath.FromSlash(target))
+	if real, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = real
+	}
+	if !insideDir(destDir, resolved) {
+		return fmt.Errorf("illegal symlink %s -> %s: target outside install directory", fpath, target)
+	}
+
+	if err := os.Remove(fpath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(target, fpath)
+}
+
+// restoreDirTimes applies directory mtimes once all their entries have been written,
+// deepest first so that restoring a child doesn't touch its parent again
+func restoreDirTimes(dirTimes []entryTime) error {
+	for idx := len(dirTimes) - 1; idx >= 0; idx-- {
+		dt := dirTimes[idx]
+		if dt.modTime.IsZero() {
+			continue
+		}
+		if err := os.Chtimes(dt.path, dt.modTime, dt.modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
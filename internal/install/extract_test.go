@@ -0,0 +1,172 @@
+package install
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// tarEntry is one entry of a test archive; a non-empty link makes it a symlink
+type tarEntry struct {
+	name    string
+	link    string
+	content string
+	dir     bool
+}
+
+// buildTar returns an uncompressed tar stream holding the entries in order
+func buildTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644}
+		switch {
+		case e.dir:
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		case e.link != "":
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = e.link
+		default:
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(e.content))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTarSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		wantErr bool
+	}{
+		{
+			name: "links inside the archive are preserved",
+			entries: []tarEntry{
+				{name: "jdk/", dir: true},
+				{name: "jdk/lib/", dir: true},
+				{name: "jdk/lib/libjvm.so", content: "jvm"},
+				{name: "jdk/bin/", dir: true},
+				{name: "jdk/bin/libjvm.so", link: "../lib/libjvm.so"},
+			},
+		},
+		{
+			name: "absolute target",
+			entries: []tarEntry{
+				{name: "jdk/", dir: true},
+				{name: "jdk/passwd", link: "/etc/passwd"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative target outside",
+			entries: []tarEntry{
+				{name: "jdk/", dir: true},
+				{name: "jdk/up", link: "../.."},
+			},
+			wantErr: true,
+		},
+		{
+			name: "chain of links escaping the destination",
+			entries: []tarEntry{
+				{name: "root/", dir: true},
+				{name: "root/a", link: "."},
+				{name: "root/a/b", link: ".."},
+				{name: "root/b/evil.txt", content: "pwned"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dangling link resolved by a later link",
+			entries: []tarEntry{
+				{name: "root/", dir: true},
+				{name: "root/l", link: "y/.."},
+				{name: "root/y", link: "."},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			dest := filepath.Join(base, "dest")
+			if err := os.Mkdir(dest, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractTar(buildTar(t, tt.entries), dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractTar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// Nothing may ever be written next to the destination
+			siblings, err := os.ReadDir(base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(siblings) != 1 {
+				t.Errorf("extraction wrote outside the destination: %v", siblings)
+			}
+
+			if !tt.wantErr {
+				data, err := os.ReadFile(filepath.Join(dest, "bin", "libjvm.so"))
+				if err != nil || string(data) != "jvm" {
+					t.Errorf("symlink not preserved: %q, %v", data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFileReplacesSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+
+	base := t.TempDir()
+	dest := filepath.Join(base, "dest")
+	outside := filepath.Join(base, "outside.txt")
+	if err := os.Mkdir(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outside, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dest, "file")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFile(dest, filepath.Join(dest, "file"), bytes.NewBufferString("new"), 0644, time.Time{}); err != nil {
+		t.Fatalf("writeFile() error = %v", err)
+	}
+
+	if data, _ := os.ReadFile(outside); string(data) != "keep" {
+		t.Errorf("writeFile followed the symlink and wrote %q outside the destination", data)
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "file")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("writeFile did not replace the symlink with a regular file: %v", err)
+	}
+}
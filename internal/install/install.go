@@ -1,16 +1,18 @@
 package install
 
 import (
-	"archive/tar"
-	"archive/zip"
-	"compress/gzip"
+	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/rexqwer911/jvt/internal/download"
+	"github.com/rexqwer911/jvt/internal/lock"
 )
 
 // Installer handles Java installation
@@ -25,8 +27,20 @@ func NewInstaller(installDir string) *Installer {
 	}
 }
 
-// Install extracts a Java archive to the installation directory
-func (i *Installer) Install(archivePath, version string) error {
+// stagingPrefix marks in-progress installs inside the install directory
+const stagingPrefix = ".staging-"
+
+// stagingMarkerSuffix names the file next to a staging directory that records
+// the version it belongs to, which is also the key of the version lock
+const stagingMarkerSuffix = ".version"
+
+// Install extracts a Java archive to the installation directory and records
+// the install metadata alongside it. The archive is extracted into a staging
+// directory and only renamed into place once it has been validated, so an
+// interrupted install never shows up as installed.
+func (i *Installer) Install(ctx context.Context, archivePath string, meta *Metadata) error {
+	version := meta.Version
+
 	// Ensure install directory exists
 	if err := os.MkdirAll(i.installDir, 0755); err != nil {
 		return fmt.Errorf("failed to create install directory: %w", err)
@@ -34,200 +48,273 @@ func (i *Installer) Install(archivePath, version string) error {
 
 	versionDir := filepath.Join(i.installDir, version)
 
+	// Serialize with other jvt processes touching this version
+	l, err := i.lockVersion(ctx, version)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
 	// Check if version already installed
 	if _, err := os.Stat(versionDir); err == nil {
-		return fmt.Errorf("version %s is already installed", version)
+		return &AlreadyInstalledError{Version: version}
 	}
 
-	// Create version directory
-	if err := os.MkdirAll(versionDir, 0755); err != nil {
-		return fmt.Errorf("failed to create version directory: %w", err)
+	stagingDir, err := i.stage(archivePath, meta)
+	if err != nil {
+		return err
 	}
 
-	// Extract archive
-	fmt.Printf("Extracting to %s...\n", versionDir)
+	// Move the validated tree into place in a single step
+	if err := os.Rename(stagingDir, versionDir); err != nil {
+		removeStaging(stagingDir)
+		return fmt.Errorf("failed to move installation into place: %w", err)
+	}
+	os.Remove(stagingDir + stagingMarkerSuffix)
 
-	if strings.HasSuffix(archivePath, ".zip") {
-		if err := i.extractZip(archivePath, versionDir); err != nil {
-			os.RemoveAll(versionDir) // Clean up on error
-			return fmt.Errorf("failed to extract zip archive: %w", err)
+	if meta.JavaHomeSubpath != "" {
+		if err := registerBundle(versionDir, version); err != nil {
+			fmt.Printf("Warning: failed to register bundle with java_home: %v\n", err)
 		}
-	} else if strings.HasSuffix(archivePath, ".tar.gz") {
-		if err := i.extractTarGz(archivePath, versionDir); err != nil {
-			os.RemoveAll(versionDir) // Clean up on error
-			return fmt.Errorf("failed to extract tar.gz archive: %w", err)
-		}
-	} else {
-		os.RemoveAll(versionDir)
-		return fmt.Errorf("unsupported archive format: %s", archivePath)
 	}
 
 	fmt.Printf("Java %s installed successfully!\n", version)
 	return nil
 }
 
-// extractZip extracts a zip archive to the destination directory
-func (i *Installer) extractZip(archivePath, destDir string) error {
-	r, err := zip.OpenReader(archivePath)
+// Repair re-extracts an installed version from its archive and swaps the fresh
+// tree in place of the existing one
+func (i *Installer) Repair(ctx context.Context, archivePath string, meta *Metadata) error {
+	version := meta.Version
+	versionDir := filepath.Join(i.installDir, version)
+
+	l, err := i.lockVersion(ctx, version)
 	if err != nil {
 		return err
 	}
-	defer r.Close()
+	defer l.Release()
 
-	// Find the root directory in the archive (usually jdk-x.x.x+x)
-	var rootDir string
-	if len(r.File) > 0 {
-		firstFile := r.File[0].Name
-		parts := strings.Split(firstFile, "/")
-		if len(parts) > 0 {
-			rootDir = parts[0]
-		}
+	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
+		return &NotInstalledError{Version: version}
 	}
 
-	for _, f := range r.File {
-		// Skip the root directory itself
-		relativePath := f.Name
-		if rootDir != "" && strings.HasPrefix(relativePath, rootDir+"/") {
-			relativePath = strings.TrimPrefix(relativePath, rootDir+"/")
-		}
-
-		if relativePath == "" {
-			continue
-		}
+	stagingDir, err := i.stage(archivePath, meta)
+	if err != nil {
+		return err
+	}
 
-		fpath := filepath.Join(destDir, relativePath)
+	// Move the damaged tree aside under a staging name, so it is swept up
+	// if we are interrupted before removing it ourselves
+	backupDir := filepath.Join(i.installDir, stagingPrefix+version+"-old")
+	removeStaging(backupDir)
+	if err := writeStagingMarker(backupDir, version); err != nil {
+		removeStaging(stagingDir)
+		return err
+	}
+	if err := os.Rename(versionDir, backupDir); err != nil {
+		removeStaging(stagingDir)
+		removeStaging(backupDir)
+		return fmt.Errorf("failed to move damaged installation aside: %w", err)
+	}
 
-		// Check for ZipSlip vulnerability
-		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path: %s", fpath)
-		}
+	if err := os.Rename(stagingDir, versionDir); err != nil {
+		os.Rename(backupDir, versionDir) // Put the old tree back
+		removeStaging(stagingDir)
+		removeStaging(backupDir)
+		return fmt.Errorf("failed to move installation into place: %w", err)
+	}
+	os.Remove(stagingDir + stagingMarkerSuffix)
 
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
-				return err
-			}
-			continue
-		}
+	if err := removeStaging(backupDir); err != nil {
+		fmt.Printf("Warning: failed to remove %s: %v\n", backupDir, err)
+	}
 
-		// Create parent directories
-		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
-			return err
-		}
+	fmt.Printf("Java %s repaired successfully!\n", version)
+	return nil
+}
 
-		// Extract file
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
+// stage extracts and validates an archive in a new staging directory and writes
+// its metadata. The caller owns the returned directory; it is removed on error.
+func (i *Installer) stage(archivePath string, meta *Metadata) (string, error) {
+	// Pick the extractor from the archive contents rather than its name
+	format, err := detectArchiveFormat(archivePath)
+	if err != nil {
+		return "", err
+	}
 
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
-			return err
-		}
+	// Stage next to the final location so the rename stays on one filesystem
+	stagingDir, err := os.MkdirTemp(i.installDir, stagingPrefix+meta.Version+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
 
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
+	if err := writeStagingMarker(stagingDir, meta.Version); err != nil {
+		removeStaging(stagingDir)
+		return "", err
+	}
 
-		if err != nil {
-			return err
-		}
+	if err := i.populateStaging(archivePath, format, stagingDir, meta); err != nil {
+		removeStaging(stagingDir) // Clean up on error
+		return "", err
 	}
 
+	return stagingDir, nil
+}
+
+// writeStagingMarker records the version a staging directory belongs to, so an
+// interrupted install can be swept up under the same version lock
+func writeStagingMarker(stagingDir, version string) error {
+	if err := os.WriteFile(stagingDir+stagingMarkerSuffix, []byte(version), 0644); err != nil {
+		return fmt.Errorf("failed to write staging marker: %w", err)
+	}
 	return nil
 }
 
-// extractTarGz extracts a tar.gz archive to the destination directory
-func (i *Installer) extractTarGz(archivePath, destDir string) error {
-	file, err := os.Open(archivePath)
-	if err != nil {
+// removeStaging removes a staging directory and its marker
+func removeStaging(stagingDir string) error {
+	err := os.RemoveAll(stagingDir)
+	os.Remove(stagingDir + stagingMarkerSuffix)
+	return err
+}
+
+// populateStaging extracts the archive into stagingDir, validates it and records the metadata
+func (i *Installer) populateStaging(archivePath string, format archiveFormat, stagingDir string, meta *Metadata) error {
+	// MkdirTemp creates the directory as 0700
+	if err := os.Chmod(stagingDir, 0755); err != nil {
 		return err
 	}
-	defer file.Close()
 
-	uncompressedStream, err := gzip.NewReader(file)
+	// Extract archive
+	fmt.Printf("Extracting %s...\n", filepath.Base(archivePath))
+
+	if err := extractors[format](i, archivePath, stagingDir); err != nil {
+		return fmt.Errorf("failed to extract %s archive: %w", format, err)
+	}
+
+	// macOS bundles keep the JDK under Contents/Home
+	meta.JavaHomeSubpath = detectJavaHomeSubpath(stagingDir)
+	javaHome := filepath.Join(stagingDir, filepath.FromSlash(meta.JavaHomeSubpath))
+
+	// Make sure the extracted tree really is the requested JDK
+	release, err := validateRelease(javaHome, meta)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid Java installation: %w", err)
 	}
-	defer uncompressedStream.Close()
+	meta.Release = release
 
-	tarReader := tar.NewReader(uncompressedStream)
+	if _, err := os.Stat(javaBinary(javaHome)); err != nil {
+		return fmt.Errorf("invalid Java installation: %s not found", filepath.Join("bin", filepath.Base(javaBinary(javaHome))))
+	}
 
-	// Find the root directory
-	// In tar.gz, we invoke Next() to get headers. We can't easily peek first like in zip.
-	// So we'll have to detect rootDir on the fly or assuming the first entry determines it if it's a dir.
-	// However, tar ordering isn't guaranteed to be depth-first strictly, but usually is.
+	// Record every extracted file so the tree can be verified later
+	manifest, err := buildFileManifest(stagingDir, meta.Version)
+	if err != nil {
+		return fmt.Errorf("failed to record file manifest: %w", err)
+	}
+	if err := writeFileManifest(stagingDir, manifest); err != nil {
+		return err
+	}
 
-	// A simpler approach for stripping root component:
-	// Determine root component from the first entry.
-	var rootDir string
+	// Make sure the JDK actually runs here before it is moved into place
+	fmt.Println("Verifying installation...")
+	if err := smokeTest(javaHome, meta.Version); err != nil {
+		return fmt.Errorf("post-install check failed: %w", err)
+	}
 
-	for {
-		header, err := tarReader.Next()
+	// Record where this version came from
+	meta.InstalledAt = time.Now().UTC()
+	return writeMetadata(stagingDir, meta)
+}
 
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
+// CleanupStaging removes staging directories left behind by interrupted installs
+func (i *Installer) CleanupStaging() error {
+	entries, err := os.ReadDir(i.installDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
 		}
+		return err
+	}
 
-		if rootDir == "" {
-			parts := strings.Split(header.Name, "/")
-			if len(parts) > 0 {
-				rootDir = parts[0]
-			}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, stagingPrefix) {
+			continue
 		}
 
-		relativePath := header.Name
-		if rootDir != "" && strings.HasPrefix(relativePath, rootDir+"/") {
-			relativePath = strings.TrimPrefix(relativePath, rootDir+"/")
+		// Each staging directory has a marker naming its version, written
+		// while the version lock is held. Without a marker the directory may
+		// have just been created, so it is left alone; a marker without a
+		// directory is left over from a finished install.
+		stagingDir := filepath.Join(i.installDir, name)
+		if entry.IsDir() {
+			if _, err := os.Stat(stagingDir + stagingMarkerSuffix); err != nil {
+				continue
+			}
+		} else if strings.HasSuffix(name, stagingMarkerSuffix) {
+			stagingDir = strings.TrimSuffix(stagingDir, stagingMarkerSuffix)
+			if _, err := os.Stat(stagingDir); !os.IsNotExist(err) {
+				continue
+			}
+		} else {
+			continue
 		}
 
-		if relativePath == "" {
+		version, err := os.ReadFile(stagingDir + stagingMarkerSuffix)
+		if err != nil || download.ValidateFileName(string(version)) != nil {
 			continue
 		}
 
-		fpath := filepath.Join(destDir, relativePath)
-
-		// Check for ZipSlip
-		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path: %s", fpath)
+		// If the version lock is free, no jvt process is still using the directory
+		l, err := lock.TryAcquire(i.versionLockPath(string(version)))
+		if err != nil {
+			continue
 		}
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(fpath, 0755); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
-				return err
-			}
-			outFile, err := os.OpenFile(fpath, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				return err
-			}
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				outFile.Close()
-				return err
-			}
-			outFile.Close()
+		err = removeStaging(stagingDir)
+		l.Release()
+		if err != nil {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
 		}
 	}
 
 	return nil
 }
 
+// javaBinary returns the path of the java executable inside a JAVA_HOME
+func javaBinary(javaHome string) string {
+	name := "java"
+	if runtime.GOOS == "windows" {
+		name = "java.exe"
+	}
+	return filepath.Join(javaHome, "bin", name)
+}
+
+// versionLockPath returns the lock file guarding a version directory
+func (i *Installer) versionLockPath(version string) string {
+	return filepath.Join(i.installDir, "."+version+".lock")
+}
+
+// lockVersion takes the cross-process lock for a version directory
+func (i *Installer) lockVersion(ctx context.Context, version string) (*lock.Lock, error) {
+	return lock.Acquire(ctx, i.versionLockPath(version), "Java "+version, lock.DefaultTimeout)
+}
+
 // Uninstall removes an installed Java version
-func (i *Installer) Uninstall(version string) error {
+func (i *Installer) Uninstall(ctx context.Context, version string) error {
 	versionDir := filepath.Join(i.installDir, version)
 
+	l, err := i.lockVersion(ctx, version)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
 	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
-		return fmt.Errorf("version %s is not installed", version)
+		return &NotInstalledError{Version: version}
 	}
 
+	unregisterBundle(version)
+
 	if err := os.RemoveAll(versionDir); err != nil {
 		return fmt.Errorf("failed to remove version: %w", err)
 	}
@@ -248,7 +335,8 @@ func (i *Installer) ListInstalled() ([]string, error) {
 
 	var versions []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		// Skip staging directories and other hidden entries
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
 			versions = append(versions, entry.Name())
 		}
 	}
@@ -256,6 +344,16 @@ func (i *Installer) ListInstalled() ([]string, error) {
 	return versions, nil
 }
 
+// CheckLayout reports whether an installed version directory still contains a
+// runnable JDK, i.e. its JAVA_HOME has a java binary
+func (i *Installer) CheckLayout(version string) error {
+	javaHome := i.GetJavaHome(version)
+	if _, err := os.Stat(javaBinary(javaHome)); err != nil {
+		return fmt.Errorf("%s not found", javaBinary(javaHome))
+	}
+	return nil
+}
+
 // IsInstalled checks if a version is installed
 func (i *Installer) IsInstalled(version string) bool {
 	versionDir := filepath.Join(i.installDir, version)
@@ -263,9 +361,29 @@ func (i *Installer) IsInstalled(version string) bool {
 	return err == nil
 }
 
-// GetJavaHome returns the JAVA_HOME path for a version
+// GetJavaHome returns the JAVA_HOME path for a version. This is the version
+// directory itself, or its Contents/Home subdirectory for macOS bundles.
 func (i *Installer) GetJavaHome(version string) string {
-	return filepath.Join(i.installDir, version)
+	versionDir := filepath.Join(i.installDir, version)
+
+	if meta, err := i.ReadMetadata(version); err == nil {
+		return filepath.Join(versionDir, filepath.FromSlash(meta.JavaHomeSubpath))
+	}
+
+	// Older installs have no metadata, so inspect the layout
+	return filepath.Join(versionDir, filepath.FromSlash(detectJavaHomeSubpath(versionDir)))
+}
+
+// detectJavaHomeSubpath returns the path of JAVA_HOME relative to the version
+// directory, in slash form ("" when the version directory is JAVA_HOME)
+func detectJavaHomeSubpath(versionDir string) string {
+	if _, err := os.Stat(filepath.Join(versionDir, "bin")); err == nil {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(versionDir, "Contents", "Home", "bin")); err == nil {
+		return "Contents/Home"
+	}
+	return ""
 }
 
 // GetInstalledByMajor returns installed versions for a specific major version
@@ -330,4 +448,3 @@ func (i *Installer) GetInstalledMajorVersions() ([]int, error) {
 	sort.Sort(sort.Reverse(sort.IntSlice(result)))
 	return result, nil
 }
-
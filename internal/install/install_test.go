@@ -0,0 +1,71 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rexqwer911/jvt/internal/lock"
+)
+
+func TestCleanupStaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		dir       string
+		marker    string
+		noMarker  bool
+		noDir     bool
+		locked    string
+		wantSwept bool
+	}{
+		{name: "interrupted install", dir: ".staging-21.0.2+13-123", marker: "21.0.2+13", wantSwept: true},
+		{name: "install in progress", dir: ".staging-21.0.2+13-123", marker: "21.0.2+13", locked: "21.0.2+13"},
+		{
+			name:   "dashes in the version and the suffix",
+			dir:    ".staging-21.0.2+13-custom-12-34",
+			marker: "21.0.2+13-custom",
+			locked: "21.0.2+13-custom",
+		},
+		{name: "directory without a marker yet", dir: ".staging-21.0.2+13-123", noMarker: true},
+		{name: "marker left over from a finished install", dir: ".staging-21.0.2+13-123", marker: "21.0.2+13", noDir: true, wantSwept: true},
+		{name: "marker with a path", dir: ".staging-x-123", marker: "../x", wantSwept: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installDir := t.TempDir()
+			installer := NewInstaller(installDir)
+
+			stagingDir := filepath.Join(installDir, tt.dir)
+			if !tt.noDir {
+				if err := os.MkdirAll(filepath.Join(stagingDir, "bin"), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !tt.noMarker {
+				if err := writeStagingMarker(stagingDir, tt.marker); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.locked != "" {
+				l, err := lock.TryAcquire(installer.versionLockPath(tt.locked))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer l.Release()
+			}
+
+			if err := installer.CleanupStaging(); err != nil {
+				t.Fatalf("CleanupStaging() error = %v", err)
+			}
+
+			_, dirErr := os.Stat(stagingDir)
+			_, markerErr := os.Stat(stagingDir + stagingMarkerSuffix)
+			swept := os.IsNotExist(dirErr) && os.IsNotExist(markerErr)
+			if swept != tt.wantSwept {
+				t.Errorf("swept = %v, want %v (directory: %v, marker: %v)", swept, tt.wantSwept, dirErr, markerErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package install
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// FileManifestName is the name of the per-file manifest written into each version directory
+const FileManifestName = ".jvt-files.json"
+
+// FileEntry records the state of one extracted file
+type FileEntry struct {
+	Path   string      `json:"path"`
+	Size   int64       `json:"size"`
+	Mode   fs.FileMode `json:"mode"`
+	SHA256 string      `json:"sha256,omitempty"`
+	Link   string      `json:"link,omitempty"`
+}
+
+// FileManifest lists every file extracted for a version
+type FileManifest struct {
+	Version string      `json:"version"`
+	Files   []FileEntry `json:"files"`
+}
+
+// FileProblem describes a difference between an installed tree and its manifest
+type FileProblem struct {
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+}
+
+// buildFileManifest walks an extracted tree and records every file and symlink
+func buildFileManifest(root, version string) (*FileManifest, error) {
+	files, err := scanTree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &FileManifest{Version: version}
+	for _, path := range sortedKeys(files) {
+		manifest.Files = append(manifest.Files, files[path])
+	}
+
+	return manifest, nil
+}
+
+// writeFileManifest writes the file manifest into the given version directory
+func writeFileManifest(versionDir string, manifest *FileManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode file manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(versionDir, FileManifestName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write file manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ReadFileManifest returns the file manifest recorded for an installed version.
+// Versions installed before manifests were introduced return an error
+// satisfying os.IsNotExist.
+func (i *Installer) ReadFileManifest(version string) (*FileManifest, error) {
+	data, err := os.ReadFile(filepath.Join(i.installDir, version, FileManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest FileManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse file manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// Verify compares an installed version against its recorded file manifest and
+// returns any missing, modified or unexpected files
+func (i *Installer) Verify(version string) ([]FileProblem, error) {
+	manifest, err := i.ReadFileManifest(version)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := scanTree(filepath.Join(i.installDir, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan installation: %w", err)
+	}
+
+	var problems []FileProblem
+	for _, expected := range manifest.Files {
+		got, ok := actual[expected.Path]
+		if !ok {
+			problems = append(problems, FileProblem{expected.Path, "missing"})
+			continue
+		}
+		delete(actual, expected.Path)
+
+		switch {
+		case expected.Link != got.Link:
+			problems = append(problems, FileProblem{expected.Path, "symlink target changed"})
+		case expected.Size != got.Size:
+			problems = append(problems, FileProblem{expected.Path, "size changed"})
+		case expected.SHA256 != got.SHA256:
+			problems = append(problems, FileProblem{expected.Path, "content modified"})
+		case expected.Mode != got.Mode:
+			problems = append(problems, FileProblem{expected.Path, fmt.Sprintf("mode changed from %s to %s", expected.Mode, got.Mode)})
+		}
+	}
+
+	for _, path := range sortedKeys(actual) {
+		problems = append(problems, FileProblem{path, "unexpected file"})
+	}
+
+	return problems, nil
+}
+
+// scanTree hashes every regular file and records every symlink below root,
+// keyed by slash-separated relative path. jvt's own bookkeeping files are skipped.
+func scanTree(root string) (map[string]FileEntry, error) {
+	files := make(map[string]FileEntry)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == MetadataFileName || rel == FileManifestName {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		entry := FileEntry{Path: rel, Mode: info.Mode()}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			entry.Link = target
+		} else {
+			entry.Size = info.Size()
+			sum, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			entry.SHA256 = sum
+		}
+
+		files[rel] = entry
+		return nil
+	})
+
+	return files, err
+}
+
+// hashFile returns the hex-encoded SHA-256 of a file
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sortedKeys returns the keys of a file map in sorted order
+func sortedKeys(files map[string]FileEntry) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
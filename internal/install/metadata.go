@@ -0,0 +1,61 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MetadataFileName is the name of the manifest written into each version directory
+const MetadataFileName = ".jvt-install.json"
+
+// Metadata describes where an installed Java version came from
+type Metadata struct {
+	Version      string       `json:"version"`
+	MajorVersion int          `json:"major_version"`
+	Distribution string       `json:"distribution"`
+	OS           string       `json:"os"`
+	Arch         string       `json:"arch"`
+	DownloadURL  string       `json:"download_url"`
+	Checksum     string       `json:"checksum"`
+	FileName     string       `json:"file_name"`
+	InstalledAt  time.Time    `json:"installed_at"`
+	Release      *ReleaseInfo `json:"release,omitempty"`
+
+	// JavaHomeSubpath is the location of JAVA_HOME inside the version
+	// directory in slash form, e.g. "Contents/Home" for macOS bundles
+	JavaHomeSubpath string `json:"java_home_subpath,omitempty"`
+}
+
+// writeMetadata writes the install manifest into the given version directory
+func writeMetadata(versionDir string, meta *Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install metadata: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(versionDir, MetadataFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write install metadata: %w", err)
+	}
+
+	return nil
+}
+
+// ReadMetadata returns the install manifest for an installed version.
+// Versions installed before manifests were introduced return an error
+// satisfying os.IsNotExist.
+func (i *Installer) ReadMetadata(version string) (*Metadata, error) {
+	data, err := os.ReadFile(filepath.Join(i.installDir, version, MetadataFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse install metadata: %w", err)
+	}
+
+	return &meta, nil
+}
@@ -0,0 +1,149 @@
+package install
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReleaseInfo holds the fields of interest from a JDK's `release` file
+type ReleaseInfo struct {
+	JavaVersion        string   `json:"java_version"`
+	Implementor        string   `json:"implementor,omitempty"`
+	JavaRuntimeVersion string   `json:"java_runtime_version,omitempty"`
+	OSName             string   `json:"os_name,omitempty"`
+	OSArch             string   `json:"os_arch,omitempty"`
+	Modules            []string `json:"modules,omitempty"`
+}
+
+// ParseReleaseFile parses a JDK `release` file (KEY="value" lines)
+func ParseReleaseFile(path string) (*ReleaseInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info := &ReleaseInfo{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.TrimSpace(key) {
+		case "JAVA_VERSION":
+			info.JavaVersion = value
+		case "IMPLEMENTOR":
+			info.Implementor = value
+		case "JAVA_RUNTIME_VERSION":
+			info.JavaRuntimeVersion = value
+		case "OS_NAME":
+			info.OSName = value
+		case "OS_ARCH":
+			info.OSArch = value
+		case "MODULES":
+			info.Modules = strings.Fields(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read release file: %w", err)
+	}
+
+	if info.JavaVersion == "" {
+		return nil, fmt.Errorf("release file %s has no JAVA_VERSION", path)
+	}
+
+	return info, nil
+}
+
+// ReadRelease parses the `release` file of an installed version
+func (i *Installer) ReadRelease(version string) (*ReleaseInfo, error) {
+	return ParseReleaseFile(filepath.Join(i.GetJavaHome(version), "release"))
+}
+
+// validateRelease checks that an extracted tree is a JDK of the requested version and arch
+func validateRelease(javaHome string, meta *Metadata) (*ReleaseInfo, error) {
+	info, err := ParseReleaseFile(filepath.Join(javaHome, "release"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no release file found in %s; the archive layout is not a JDK", javaHome)
+		}
+		return nil, err
+	}
+
+	if !javaVersionMatches(info.JavaVersion, meta.Version) {
+		return nil, fmt.Errorf("archive contains Java %s, expected %s", info.JavaVersion, meta.Version)
+	}
+
+	if meta.Arch != "" && info.OSArch != "" && normalizeArch(info.OSArch) != normalizeArch(meta.Arch) {
+		return nil, fmt.Errorf("archive is built for %s, expected %s", info.OSArch, meta.Arch)
+	}
+
+	return info, nil
+}
+
+// javaVersionMatches reports whether a JDK-reported version (e.g. "21.0.2" or "1.8.0_402")
+// matches a jvt version (e.g. "21.0.2+13" or "8.0.402+6"). Major versions must be equal,
+// and the security number is compared when both sides provide one.
+func javaVersionMatches(reported, version string) bool {
+	got, gotParts := parseJavaVersion(reported)
+	want, wantParts := parseJavaVersion(version)
+	if gotParts == 0 || wantParts == 0 || got[0] != want[0] {
+		return false
+	}
+	if gotParts >= 3 && wantParts >= 3 && got[2] != want[2] {
+		return false
+	}
+	return true
+}
+
+// parseJavaVersion returns [major, minor, security] and the number of parsed components.
+// Legacy "1.x.y_z" strings are normalized to [x, y, z].
+func parseJavaVersion(v string) ([3]int, int) {
+	var parts [3]int
+
+	// Drop build and pre-release suffixes ("+13", "-ea", "-LTS")
+	if idx := strings.IndexAny(v, "+-"); idx >= 0 {
+		v = v[:idx]
+	}
+
+	if strings.HasPrefix(v, "1.") {
+		v = strings.TrimPrefix(v, "1.")
+		v = strings.Replace(v, "_", ".", 1)
+	}
+
+	count := 0
+	for _, field := range strings.Split(v, ".") {
+		if count == len(parts) {
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts[count] = n
+		count++
+	}
+
+	return parts, count
+}
+
+// normalizeArch maps the various spellings of an architecture to Adoptium's names
+func normalizeArch(arch string) string {
+	switch strings.ToLower(arch) {
+	case "x64", "x86_64", "amd64":
+		return "x64"
+	case "aarch64", "arm64":
+		return "aarch64"
+	case "x32", "x86", "i386", "i586", "i686":
+		return "x32"
+	default:
+		return strings.ToLower(arch)
+	}
+}
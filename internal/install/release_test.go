@@ -0,0 +1,32 @@
+package install
+
+import "testing"
+
+func TestJavaVersionMatches(t *testing.T) {
+	tests := []struct {
+		reported string
+		version  string
+		want     bool
+	}{
+		{reported: "21.0.2", version: "21.0.2+13", want: true},
+		{reported: "21.0.2", version: "21.0.3+9", want: false},
+		{reported: "21", version: "21.0.2+13", want: true},
+		{reported: "17.0.10", version: "21.0.2+13", want: false},
+		{reported: "1.8.0_402", version: "8.0.402+6", want: true},
+		{reported: "1.8.0_402", version: "8.0.392+8", want: false},
+		{reported: "1.8.0_402", version: "18.0.2+9", want: false},
+		{reported: "1.8.0", version: "8.0.402+6", want: true},
+		{reported: "22-ea", version: "22.0.0+36", want: true},
+		{reported: "11.0.22-LTS", version: "11.0.22+7", want: true},
+		{reported: "", version: "21.0.2+13", want: false},
+		{reported: "abc", version: "21.0.2+13", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reported+" vs "+tt.version, func(t *testing.T) {
+			if got := javaVersionMatches(tt.reported, tt.version); got != tt.want {
+				t.Errorf("javaVersionMatches(%q, %q) = %v, want %v", tt.reported, tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// smokeTestTimeout bounds each `-version` invocation of the installed tools
+const smokeTestTimeout = 30 * time.Second
+
+var (
+	// javaVersionPattern matches `openjdk version "21.0.2" 2024-01-16`
+	javaVersionPattern = regexp.MustCompile(`version "([^"]+)"`)
+	// javacVersionPattern matches `javac 21.0.2`
+	javacVersionPattern = regexp.MustCompile(`javac (\S+)`)
+)
+
+// smokeTest runs `java -version` (and `javac -version` when present) from an
+// extracted JDK and checks that it executes on this host and reports the expected version
+func smokeTest(javaHome, version string) error {
+	output, err := runVersion(javaBinary(javaHome))
+	if err != nil {
+		return fmt.Errorf("java does not run on this system: %w", err)
+	}
+	if err := checkReportedVersion(output, javaVersionPattern, version); err != nil {
+		return fmt.Errorf("java -version: %w", err)
+	}
+
+	javac := filepath.Join(javaHome, "bin", "javac")
+	if runtime.GOOS == "windows" {
+		javac += ".exe"
+	}
+	if _, err := os.Stat(javac); err != nil {
+		return nil // JRE-only images have no compiler
+	}
+
+	output, err = runVersion(javac)
+	if err != nil {
+		return fmt.Errorf("javac does not run on this system: %w", err)
+	}
+	if err := checkReportedVersion(output, javacVersionPattern, version); err != nil {
+		return fmt.Errorf("javac -version: %w", err)
+	}
+
+	return nil
+}
+
+// runVersion runs `<binary> -version` with a timeout and returns its combined output
+func runVersion(binary string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, binary, "-version").CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("timed out after %s", smokeTestTimeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return string(output), nil
+}
+
+// checkReportedVersion extracts the version from tool output and compares it to the expected one
+func checkReportedVersion(output string, pattern *regexp.Regexp, version string) error {
+	match := pattern.FindStringSubmatch(output)
+	if match == nil {
+		return fmt.Errorf("could not find a version in output: %s", strings.TrimSpace(output))
+	}
+
+	if !javaVersionMatches(match[1], version) {
+		return fmt.Errorf("reports version %s, expected %s", match[1], version)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rexqwer911/jvt/internal/download"
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/version"
+)
+
+// FormatVersion is the version of the inventory document written by Collect
+const FormatVersion = 1
+
+// Inventory is a portable description of the JDKs installed on a machine
+type Inventory struct {
+	Format     int               `json:"format"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Default    string            `json:"default,omitempty"`
+	Aliases    map[string]string `json:"aliases,omitempty"`
+	Versions   []Entry           `json:"versions"`
+}
+
+// Entry is one installed version and the build it was installed from
+type Entry struct {
+	Version      string `json:"version"`
+	MajorVersion int    `json:"major_version,omitempty"`
+	Distribution string `json:"distribution,omitempty"`
+	OS           string `json:"os,omitempty"`
+	Arch         string `json:"arch,omitempty"`
+	DownloadURL  string `json:"download_url,omitempty"`
+	Checksum     string `json:"checksum,omitempty"`
+	FileName     string `json:"file_name,omitempty"`
+}
+
+// Collect describes the installed versions, the default version and the
+// aliases. Versions installed before install metadata was recorded are listed
+// by version only.
+func Collect(installer *install.Installer, mgr *version.Manager, aliases map[string]string) (*Inventory, error) {
+	versions, err := installer.ListInstalled()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed versions: %w", err)
+	}
+
+	inv := &Inventory{
+		Format:     FormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Aliases:    aliases,
+		Versions:   []Entry{},
+	}
+	inv.Default, _ = mgr.GetDefaultVersion()
+
+	for _, v := range versions {
+		entry := Entry{Version: v}
+		if meta, err := installer.ReadMetadata(v); err == nil {
+			entry.MajorVersion = meta.MajorVersion
+			entry.Distribution = meta.Distribution
+			entry.OS = meta.OS
+			entry.Arch = meta.Arch
+			entry.DownloadURL = meta.DownloadURL
+			entry.Checksum = meta.Checksum
+			entry.FileName = meta.FileName
+		}
+		inv.Versions = append(inv.Versions, entry)
+	}
+
+	return inv, nil
+}
+
+// Read parses an inventory document
+func Read(r io.Reader) (*Inventory, error) {
+	var inv Inventory
+	if err := json.NewDecoder(r).Decode(&inv); err != nil {
+		return nil, fmt.Errorf("invalid inventory: %w", err)
+	}
+
+	if inv.Format == 0 || inv.Format > FormatVersion {
+		return nil, fmt.Errorf("unsupported inventory format %d (this jvt reads format %d)", inv.Format, FormatVersion)
+	}
+	if inv.Default != "" {
+		if err := download.ValidateFileName(inv.Default); err != nil {
+			return nil, fmt.Errorf("invalid inventory: default: %w", err)
+		}
+	}
+	for idx, entry := range inv.Versions {
+		if entry.Version == "" {
+			return nil, fmt.Errorf("invalid inventory: entry %d has no version", idx+1)
+		}
+
+		// Versions name install directories and file names name cache
+		// entries, so neither may point elsewhere
+		if err := download.ValidateFileName(entry.Version); err != nil {
+			return nil, fmt.Errorf("invalid inventory: entry %d: version: %w", idx+1, err)
+		}
+		if entry.FileName != "" {
+			if err := download.ValidateFileName(entry.FileName); err != nil {
+				return nil, fmt.Errorf("invalid inventory: entry %d: file_name: %w", idx+1, err)
+			}
+		}
+	}
+
+	return &inv, nil
+}
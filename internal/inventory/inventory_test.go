@@ -0,0 +1,49 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr string
+	}{
+		{
+			name:    "valid inventory",
+			content: `{"format": 1, "default": "21.0.2+13", "versions": [{"version": "21.0.2+13", "file_name": "jdk.tar.gz"}, {"version": "17.0.10+7"}]}`,
+			want:    2,
+		},
+		{name: "empty inventory", content: `{"format": 1, "versions": []}`, want: 0},
+		{name: "not JSON", content: `versions: []`, wantErr: "invalid inventory"},
+		{name: "missing format", content: `{"versions": []}`, wantErr: "unsupported inventory format 0"},
+		{name: "newer format", content: `{"format": 2, "versions": []}`, wantErr: "unsupported inventory format 2"},
+		{name: "entry without a version", content: `{"format": 1, "versions": [{"version": "21.0.2+13"}, {"distribution": "Temurin"}]}`, wantErr: "entry 2 has no version"},
+		{name: "version with a path", content: `{"format": 1, "versions": [{"version": "../../bin"}]}`, wantErr: "entry 1: version"},
+		{name: "version naming the install directory", content: `{"format": 1, "versions": [{"version": "."}]}`, wantErr: "entry 1: version"},
+		{name: "file name with a path", content: `{"format": 1, "versions": [{"version": "21.0.2+13", "file_name": "../.bashrc"}]}`, wantErr: "entry 1: file_name"},
+		{name: "default with a path", content: `{"format": 1, "default": "../21", "versions": []}`, wantErr: "default"},
+		{name: "default naming the install directory", content: `{"format": 1, "default": ".", "versions": []}`, wantErr: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := Read(strings.NewReader(tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Read() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(inv.Versions) != tt.want {
+				t.Errorf("Read() lists %d versions, want %d", len(inv.Versions), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTimeout is how long Acquire waits for another jvt process by default
+const DefaultTimeout = 5 * time.Minute
+
+// pollInterval is how often a held lock is retried while waiting
+const pollInterval = 250 * time.Millisecond
+
+// ErrLocked is returned when a lock is held by another process
+var ErrLocked = errors.New("lock is held by another jvt process")
+
+// Lock is an advisory, cross-process file lock (flock on Unix, LockFileEx on Windows)
+type Lock struct {
+	file *os.File
+}
+
+// TryAcquire takes an exclusive lock on path without waiting.
+// It returns ErrLocked if another process holds the lock.
+func TryAcquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	ok, err := tryLock(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if !ok {
+		file.Close()
+		return nil, ErrLocked
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Acquire takes an exclusive lock on path, waiting up to timeout for another
+// jvt process to release it. The wait ends early with ctx.Err() when ctx is
+// cancelled. The description names the guarded resource in messages shown to
+// the user (e.g. "Java 21.0.2+13").
+func Acquire(ctx context.Context, path, description string, timeout time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(timeout)
+	waiting := false
+
+	for {
+		l, err := TryAcquire(path)
+		if err == nil {
+			return l, nil
+		}
+		if !errors.Is(err, ErrLocked) {
+			return nil, err
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("another jvt process is still using %s; try again once it finishes (lock file: %s)", description, path)
+		}
+
+		if !waiting {
+			fmt.Printf("Waiting for another jvt process using %s...\n", description)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Release releases the lock. The lock file itself is left in place.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
@@ -0,0 +1,218 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// barWidth is the number of cells of each progress bar
+	barWidth = 30
+
+	// redrawInterval is how often a live display is redrawn
+	redrawInterval = 150 * time.Millisecond
+)
+
+// Multi shows the progress of several concurrent transfers: one bar per
+// transfer and a total. On a terminal the bars are redrawn in place; otherwise
+// a line is printed when a transfer starts and when it ends.
+type Multi struct {
+	w    io.Writer
+	live bool
+
+	mu    sync.Mutex
+	bars  []*Bar
+	drawn int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Bar is the progress of one transfer. Bytes written to it count as transferred.
+type Bar struct {
+	m       *Multi
+	name    string
+	total   int64
+	current int64
+	// state is "" while running, then "done" or "failed"
+	state string
+	err   error
+}
+
+// NewMulti creates a display writing to w. Live displays redraw their bars
+// with terminal escape sequences and should only be used on a terminal.
+func NewMulti(w io.Writer, live bool) *Multi {
+	if f, ok := w.(*os.File); ok && live {
+		live = enableEscapes(f)
+	}
+	return &Multi{w: w, live: live}
+}
+
+// Add adds a transfer of total bytes; total may be 0 if it is not known yet
+func (m *Multi) Add(name string, total int64) *Bar {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bar := &Bar{m: m, name: name, total: total}
+	m.bars = append(m.bars, bar)
+	if !m.live {
+		fmt.Fprintf(m.w, "Downloading %s...\n", name)
+	}
+	return bar
+}
+
+// Start begins redrawing a live display in the background
+func (m *Multi) Start() {
+	if !m.live {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(redrawInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.mu.Lock()
+				m.render()
+				m.mu.Unlock()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the display, drawing the final state of a live display
+func (m *Multi) Stop() {
+	if !m.live || m.stop == nil {
+		return
+	}
+
+	close(m.stop)
+	<-m.done
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.render()
+}
+
+// SetTotal sets the size of the transfer once it is known; sizes <= 0 are ignored
+func (b *Bar) SetTotal(total int64) {
+	if total <= 0 {
+		return
+	}
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.total = total
+}
+
+// Write counts p as transferred
+func (b *Bar) Write(p []byte) (int, error) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+	b.current += int64(len(p))
+	return len(p), nil
+}
+
+// Finish marks the transfer as complete, or as failed if err is not nil
+func (b *Bar) Finish(err error) {
+	b.m.mu.Lock()
+	defer b.m.mu.Unlock()
+
+	if err != nil {
+		b.state, b.err = "failed", err
+	} else {
+		b.state = "done"
+		if b.total < b.current {
+			b.total = b.current
+		}
+		b.current = b.total
+	}
+
+	if !b.m.live {
+		if err != nil {
+			fmt.Fprintf(b.m.w, "✗ %s: %v\n", b.name, err)
+		} else {
+			fmt.Fprintf(b.m.w, "✓ Downloaded %s (%s)\n", b.name, formatBytes(b.total))
+		}
+	}
+}
+
+// render redraws every bar and the total over the previous drawing; the
+// caller holds m.mu
+func (m *Multi) render() {
+	if len(m.bars) == 0 {
+		return
+	}
+
+	width := len("Total")
+	for _, b := range m.bars {
+		width = max(width, len(b.name))
+	}
+
+	var sb strings.Builder
+	if m.drawn > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dA", m.drawn)
+	}
+
+	var current, total int64
+	finished := 0
+	for _, b := range m.bars {
+		current += b.current
+		total += max(b.total, b.current)
+		if b.state != "" {
+			finished++
+		}
+
+		sb.WriteString("\r\x1b[K")
+		if b.state == "failed" {
+			fmt.Fprintf(&sb, "%-*s  failed: %v\n", width, b.name, b.err)
+		} else {
+			fmt.Fprintf(&sb, "%-*s  %s\n", width, b.name, formatBar(b.current, b.total))
+		}
+	}
+
+	sb.WriteString("\r\x1b[K")
+	fmt.Fprintf(&sb, "%-*s  %s  %d/%d done\n", width, "Total", formatBar(current, total), finished, len(m.bars))
+
+	m.drawn = len(m.bars) + 1
+	io.WriteString(m.w, sb.String())
+}
+
+// formatBar draws a bar with its percentage and byte counts; without a known
+// total only the transferred bytes are shown
+func formatBar(current, total int64) string {
+	if total <= 0 {
+		return fmt.Sprintf("[%s] %s", strings.Repeat(" ", barWidth), formatBytes(current))
+	}
+
+	current = min(current, total)
+	filled := int(current * barWidth / total)
+	pct := current * 100 / total
+	return fmt.Sprintf("[%s%s] %3d%% %s/%s",
+		strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled),
+		pct, formatBytes(current), formatBytes(total))
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "187.3 MB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for q := n / unit; q >= unit; q /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
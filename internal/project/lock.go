@@ -0,0 +1,95 @@
+package project
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rexqwer911/jvt/internal/download"
+)
+
+// LockFileName is the per-project file recording the exact JDK builds a version spec resolved to
+const LockFileName = "jvt.lock"
+
+// Lock records the build a version spec resolved to, for every platform it is published for
+type Lock struct {
+	Spec         string                 `json:"spec"`
+	Distribution string                 `json:"distribution"`
+	Version      string                 `json:"version"`
+	MajorVersion int                    `json:"major_version"`
+	Platforms    map[string]LockedBuild `json:"platforms"`
+}
+
+// LockedBuild is the archive of a locked version for one platform
+type LockedBuild struct {
+	FileName string `json:"file_name"`
+	URL      string `json:"url"`
+	SHA256   string `json:"sha256"`
+}
+
+// PlatformKey names a platform in the lock file, e.g. linux-x64 or mac-aarch64
+func PlatformKey(os, arch string) string {
+	return os + "-" + arch
+}
+
+// ReadLock reads a lock file
+func ReadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock Lock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if lock.Version == "" || len(lock.Platforms) == 0 {
+		return nil, fmt.Errorf("invalid %s: no locked builds", path)
+	}
+
+	// The version names the install directory and the file names name cache
+	// entries, so neither may point elsewhere. A build without a checksum
+	// could be swapped under the lock, so every build needs one.
+	if err := download.ValidateFileName(lock.Version); err != nil {
+		return nil, fmt.Errorf("invalid %s: version: %w", path, err)
+	}
+	for key, build := range lock.Platforms {
+		if err := download.ValidateFileName(build.FileName); err != nil {
+			return nil, fmt.Errorf("invalid %s: platforms.%s.file_name: %w", path, key, err)
+		}
+		if !isSHA256(build.SHA256) {
+			return nil, fmt.Errorf("invalid %s: platforms.%s.sha256: %q is not a SHA-256 checksum", path, key, build.SHA256)
+		}
+	}
+	return &lock, nil
+}
+
+// isSHA256 reports whether s is a hex-encoded SHA-256 checksum
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// WriteLock writes a lock file atomically
+func WriteLock(path string, lock *Lock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// FindLock returns the path of the lock file in dir or its nearest parent
+// that has one, or "" if there is none
+func FindLock(dir string) (string, error) {
+	return findUp(dir, LockFileName)
+}
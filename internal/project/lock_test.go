@@ -0,0 +1,107 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sum is a well-formed SHA-256 checksum
+var sum = strings.Repeat("ab", 32)
+
+func TestReadLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "valid lock",
+			content: `{"spec": "21", "version": "21.0.2+13", "platforms": {"linux-x64": {"file_name": "jdk.tar.gz", "sha256": "` + sum + `"}}}`,
+		},
+		{
+			name:    "upper-case checksum",
+			content: `{"version": "21.0.2+13", "platforms": {"linux-x64": {"file_name": "jdk.tar.gz", "sha256": "` + strings.ToUpper(sum) + `"}}}`,
+		},
+		{name: "not JSON", content: `version = 21`, wantErr: "invalid"},
+		{name: "no version", content: `{"platforms": {"linux-x64": {"file_name": "jdk.tar.gz", "sha256": "` + sum + `"}}}`, wantErr: "no locked builds"},
+		{name: "no platforms", content: `{"version": "21.0.2+13", "platforms": {}}`, wantErr: "no locked builds"},
+		{
+			name:    "version with a path",
+			content: `{"version": "../21", "platforms": {"linux-x64": {"file_name": "jdk.tar.gz", "sha256": "` + sum + `"}}}`,
+			wantErr: "version",
+		},
+		{
+			name:    "file name with a path",
+			content: `{"version": "21.0.2+13", "platforms": {"linux-x64": {"file_name": "../../.bashrc", "sha256": "` + sum + `"}}}`,
+			wantErr: "platforms.linux-x64.file_name",
+		},
+		{
+			name:    "empty file name",
+			content: `{"version": "21.0.2+13", "platforms": {"linux-x64": {"sha256": "` + sum + `"}}}`,
+			wantErr: "platforms.linux-x64.file_name",
+		},
+		{
+			name:    "missing checksum",
+			content: `{"version": "21.0.2+13", "platforms": {"linux-x64": {"file_name": "jdk.tar.gz"}}}`,
+			wantErr: "platforms.linux-x64.sha256",
+		},
+		{
+			name:    "short checksum",
+			content: `{"version": "21.0.2+13", "platforms": {"linux-x64": {"file_name": "jdk.tar.gz", "sha256": "abc"}}}`,
+			wantErr: "platforms.linux-x64.sha256",
+		},
+		{
+			name:    "checksum that is not hex",
+			content: `{"version": "21.0.2+13", "platforms": {"linux-x64": {"file_name": "jdk.tar.gz", "sha256": "` + strings.Repeat("zz", 32) + `"}}}`,
+			wantErr: "platforms.linux-x64.sha256",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), LockFileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := ReadLock(path)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ReadLock() error = %v, want none", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ReadLock() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteLockRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+	lock := &Lock{
+		Spec:         "21",
+		Distribution: "Temurin",
+		Version:      "21.0.2+13",
+		MajorVersion: 21,
+		Platforms: map[string]LockedBuild{
+			PlatformKey("linux", "x64"):   {FileName: "jdk-linux.tar.gz", URL: "https://example.com/jdk-linux.tar.gz", SHA256: sum},
+			PlatformKey("mac", "aarch64"): {FileName: "jdk-mac.tar.gz", URL: "https://example.com/jdk-mac.tar.gz", SHA256: sum},
+		},
+	}
+
+	if err := WriteLock(path, lock); err != nil {
+		t.Fatalf("WriteLock() error = %v", err)
+	}
+	got, err := ReadLock(path)
+	if err != nil {
+		t.Fatalf("ReadLock() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, lock) {
+		t.Errorf("ReadLock() = %+v, want %+v", got, lock)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
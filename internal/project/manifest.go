@@ -0,0 +1,60 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ManifestFileName is the per-project file listing every JDK a repository needs
+const ManifestFileName = "jvt.toml"
+
+// Manifest lists the JDKs a project requires
+type Manifest struct {
+	JDKs []ManifestJDK `toml:"jdk"`
+}
+
+// ManifestJDK is one JDK required by a manifest
+type ManifestJDK struct {
+	// Name describes what the JDK is used for, e.g. "legacy module"
+	Name string `toml:"name"`
+	// Version is a version spec such as "17" or "21.0.2+13"
+	Version string `toml:"version"`
+	// Distribution optionally restricts the JDK to one distribution
+	Distribution string `toml:"distribution"`
+}
+
+// ReadManifest reads and validates a manifest
+func ReadManifest(path string) (*Manifest, error) {
+	var manifest Manifest
+	meta, err := toml.DecodeFile(path, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for idx, key := range undecoded {
+			keys[idx] = key.String()
+		}
+		return nil, fmt.Errorf("invalid %s: unknown keys %s", path, strings.Join(keys, ", "))
+	}
+
+	if len(manifest.JDKs) == 0 {
+		return nil, fmt.Errorf("invalid %s: no [[jdk]] entries", path)
+	}
+	for idx, jdk := range manifest.JDKs {
+		if strings.TrimSpace(jdk.Version) == "" {
+			return nil, fmt.Errorf("invalid %s: [[jdk]] entry %d has no version", path, idx+1)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// FindManifest returns the path of the manifest in dir or its nearest parent
+// that has one, or "" if there is none
+func FindManifest(dir string) (string, error) {
+	return findUp(dir, ManifestFileName)
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VersionFileName is the per-project file naming the Java version a project uses
+const VersionFileName = ".java-version"
+
+// Pin is a version requirement found in a project file
+type Pin struct {
+	Spec   string
+	Source string
+}
+
+// FindPins looks for project version files, lock files and manifests in dir
+// and each of its parents and returns the versions they require, nearest first
+func FindPins(dir string) ([]Pin, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pins []Pin
+	for {
+		if spec := VersionSpec(dir); spec != "" {
+			pins = append(pins, Pin{Spec: spec, Source: filepath.Join(dir, VersionFileName)})
+		}
+
+		lockPath := filepath.Join(dir, LockFileName)
+		if lock, err := ReadLock(lockPath); err == nil {
+			pins = append(pins, Pin{Spec: lock.Version, Source: lockPath})
+		}
+
+		manifestPath := filepath.Join(dir, ManifestFileName)
+		if manifest, err := ReadManifest(manifestPath); err == nil {
+			for _, jdk := range manifest.JDKs {
+				pins = append(pins, Pin{Spec: jdk.Version, Source: manifestPath})
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return pins, nil
+}
+
+// VersionSpec returns the version required by the version file in dir, or ""
+// if dir has none
+func VersionSpec(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, VersionFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// findUp returns the path of the file called name in dir or its nearest
+// parent that has one, or "" if there is none
+func findUp(dir, name string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
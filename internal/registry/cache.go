@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheMode controls how the on-disk catalogue cache is used
+type CacheMode int
+
+const (
+	// CacheDefault uses the cache while it is fresh and revalidates it afterwards
+	CacheDefault CacheMode = iota
+	// CacheRefresh always revalidates the cache against the remote registry
+	CacheRefresh
+	// CacheOffline only uses the cache and never touches the network
+	CacheOffline
+)
+
+// catalogueCache is the on-disk copy of the registry API responses
+type catalogueCache struct {
+	FetchedAt time.Time                 `json:"fetched_at"`
+	Responses map[string]cachedResponse `json:"responses"`
+}
+
+// cachedResponse is one API response body with the ETag it was served with
+type cachedResponse struct {
+	ETag string          `json:"etag,omitempty"`
+	Body json.RawMessage `json:"body"`
+}
+
+// EnableCache makes the registry read and write its catalogue at path. A cache
+// younger than ttl is used without contacting the remote registry.
+func (r *Registry) EnableCache(path string, ttl time.Duration, mode CacheMode) {
+	r.cachePath = path
+	r.cacheTTL = ttl
+	r.cacheMode = mode
+}
+
+// loadCache reads the cache file. A missing or unreadable cache is treated as empty,
+// except in offline mode where it is the only source of data.
+func (r *Registry) loadCache() error {
+	r.cache = &catalogueCache{Responses: map[string]cachedResponse{}}
+	if r.cachePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.cachePath)
+	if err == nil {
+		var c catalogueCache
+		if err = json.Unmarshal(data, &c); err == nil && c.Responses != nil {
+			r.cache = &c
+		}
+	}
+
+	if r.cacheMode == CacheOffline && len(r.cache.Responses) == 0 {
+		return &NetworkError{URL: r.apiURL, Err: fmt.Errorf("no cached version catalogue available offline; run once without --offline")}
+	}
+
+	return nil
+}
+
+// cacheIsFresh reports whether cached responses can be used without revalidation
+func (r *Registry) cacheIsFresh() bool {
+	switch r.cacheMode {
+	case CacheOffline:
+		return true
+	case CacheRefresh:
+		return false
+	default:
+		return r.cachePath != "" && time.Since(r.cache.FetchedAt) < r.cacheTTL
+	}
+}
+
+// saveCache writes a catalogue to the cache file
+func (r *Registry) saveCache(c *catalogueCache) error {
+	if r.cachePath == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.cachePath), 0755); err != nil {
+		return err
+	}
+
+	// Concurrent jvt processes each write their own temporary file, so the
+	// cache is always replaced by one complete copy
+	tmp, err := os.CreateTemp(filepath.Dir(r.cachePath), filepath.Base(r.cachePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, r.cachePath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// CachedAt returns when the catalogue in use was fetched from the remote registry
+func (r *Registry) CachedAt() time.Time {
+	if r.cache == nil {
+		return time.Time{}
+	}
+	return r.cache.FetchedAt
+}
+
+// CheckCache reports whether the cache file at path can be read. A missing
+// cache is not an error.
+func CheckCache(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var c catalogueCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("invalid catalogue cache: %w", err)
+	}
+	if c.Responses == nil {
+		return fmt.Errorf("invalid catalogue cache: no responses recorded")
+	}
+	return nil
+}
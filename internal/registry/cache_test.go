@@ -0,0 +1,328 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRegistry returns a registry reading the API at apiURL and caching
+// its catalogue in a temporary directory
+func newTestRegistry(t *testing.T, apiURL string, mode CacheMode) *Registry {
+	t.Helper()
+
+	r := NewRegistry()
+	r.apiURL = apiURL
+	r.EnableCache(filepath.Join(t.TempDir(), "registry.json"), time.Hour, mode)
+	return r
+}
+
+func TestCacheIsFresh(t *testing.T) {
+	tests := []struct {
+		name string
+		mode CacheMode
+		age  time.Duration
+		want bool
+	}{
+		{name: "young cache", mode: CacheDefault, age: time.Minute, want: true},
+		{name: "expired cache", mode: CacheDefault, age: 2 * time.Hour, want: false},
+		{name: "refresh ignores the age", mode: CacheRefresh, age: time.Minute, want: false},
+		{name: "offline ignores the age", mode: CacheOffline, age: 48 * time.Hour, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, "http://127.0.0.1:0", tt.mode)
+			r.cache = &catalogueCache{FetchedAt: time.Now().Add(-tt.age)}
+			if got := r.cacheIsFresh(); got != tt.want {
+				t.Errorf("cacheIsFresh() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRevalidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       CacheMode
+		age        time.Duration
+		cached     *cachedResponse
+		status     int
+		etag       string
+		body       string
+		want       string
+		wantETag   string
+		wantSent   string
+		wantErr    bool
+		wantNoCall bool
+	}{
+		{
+			name:   "changed response replaces the cached one",
+			mode:   CacheDefault,
+			age:    2 * time.Hour,
+			cached: &cachedResponse{ETag: `"v1"`, Body: json.RawMessage(`[1]`)},
+			status: http.StatusOK, etag: `"v2"`, body: `[2]`,
+			want: `[2]`, wantETag: `"v2"`, wantSent: `"v1"`,
+		},
+		{
+			name:   "304 without an ETag keeps the cached ETag",
+			mode:   CacheDefault,
+			age:    2 * time.Hour,
+			cached: &cachedResponse{ETag: `"v1"`, Body: json.RawMessage(`[1]`)},
+			status: http.StatusNotModified,
+			want:   `[1]`, wantETag: `"v1"`, wantSent: `"v1"`,
+		},
+		{
+			name:   "304 with an ETag takes the new one",
+			mode:   CacheRefresh,
+			cached: &cachedResponse{ETag: `"v1"`, Body: json.RawMessage(`[1]`)},
+			status: http.StatusNotModified, etag: `"v1b"`,
+			want: `[1]`, wantETag: `"v1b"`, wantSent: `"v1"`,
+		},
+		{
+			name:   "fresh cache is used without a request",
+			mode:   CacheDefault,
+			age:    time.Minute,
+			cached: &cachedResponse{ETag: `"v1"`, Body: json.RawMessage(`[1]`)},
+			want:   `[1]`, wantNoCall: true,
+		},
+		{
+			name:   "fresh cache without the response asks the server",
+			mode:   CacheDefault,
+			age:    time.Minute,
+			status: http.StatusOK, etag: `"v1"`, body: `[1]`,
+			want: `[1]`, wantETag: `"v1"`,
+		},
+		{
+			name:    "offline without the response fails",
+			mode:    CacheOffline,
+			wantErr: true, wantNoCall: true,
+		},
+		{
+			name:   "invalid JSON is rejected",
+			mode:   CacheRefresh,
+			status: http.StatusOK, body: `not json`,
+			wantErr: true,
+		},
+		{
+			name:    "server errors are network errors",
+			mode:    CacheRefresh,
+			status:  http.StatusInternalServerError,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			var sent string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls.Add(1)
+				sent = req.Header.Get("If-None-Match")
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+
+			url := srv.URL + "/info/available_releases"
+			r := newTestRegistry(t, srv.URL, tt.mode)
+			r.cache = &catalogueCache{FetchedAt: time.Now().Add(-tt.age), Responses: map[string]cachedResponse{}}
+			if tt.cached != nil {
+				r.cache.Responses[url] = *tt.cached
+			}
+			r.fetched = map[string]cachedResponse{}
+
+			body, err := r.get(context.Background(), url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantNoCall != (calls.Load() == 0) {
+				t.Errorf("server called %d time(s), want a call: %v", calls.Load(), !tt.wantNoCall)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if string(body) != tt.want {
+				t.Errorf("get() = %s, want %s", body, tt.want)
+			}
+			if sent != tt.wantSent {
+				t.Errorf("If-None-Match = %q, want %q", sent, tt.wantSent)
+			}
+			if got := r.fetched[url].ETag; !tt.wantNoCall && got != tt.wantETag {
+				t.Errorf("recorded ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+// fakeAdoptium serves a catalogue with one build of each major for the
+// current platform, and a release list of two builds for 21
+func fakeAdoptium(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	targetOS, targetArch := Platform()
+	latest := `[{"binary": {"os": %q, "architecture": %q, "image_type": "jdk",
+		"package": {"name": "jdk-%[3]d.tar.gz", "link": "https://example.com/jdk-%[3]d.tar.gz", "checksum": "abc"}},
+		"version": {"major": %[3]d, "minor": 0, "security": 2, "build": 13}}]`
+	release := `{"binaries": [{"os": %q, "architecture": %q, "image_type": "jdk",
+		"package": {"name": "jdk-21.0.%[3]d.tar.gz", "link": "https://example.com/jdk-21.0.%[3]d.tar.gz", "checksum": "abc"}}],
+		"version_data": {"major": 21, "minor": 0, "security": %[3]d, "build": 7}}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info/available_releases", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `{"available_releases": [17, 21]}`)
+	})
+	for _, major := range []int{17, 21} {
+		mux.HandleFunc(fmt.Sprintf("/assets/latest/%d/hotspot", major), func(w http.ResponseWriter, req *http.Request) {
+			fmt.Fprintf(w, latest, targetOS, targetArch, major)
+		})
+	}
+	mux.HandleFunc("/assets/feature_releases/21/ga", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "[%s, %s]", fmt.Sprintf(release, targetOS, targetArch, 2), fmt.Sprintf(release, targetOS, targetArch, 1))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchAvailableVersionsTwice(t *testing.T) {
+	srv := fakeAdoptium(t)
+	r := newTestRegistry(t, srv.URL, CacheRefresh)
+
+	for i := 0; i < 2; i++ {
+		if err := r.FetchAvailableVersions(context.Background()); err != nil {
+			t.Fatalf("FetchAvailableVersions() error = %v", err)
+		}
+		if got := len(r.GetVersions()); got != 2 {
+			t.Fatalf("fetch %d lists %d versions, want 2", i+1, got)
+		}
+		if got := len(r.Builds("21.0.2+13")); got != 1 {
+			t.Fatalf("fetch %d lists %d builds of 21.0.2+13, want 1", i+1, got)
+		}
+	}
+}
+
+func TestCachedCatalogueOffline(t *testing.T) {
+	tests := []struct {
+		name        string
+		releases    bool
+		wantVersion string
+		wantErr     bool
+	}{
+		{name: "catalogue is read offline", wantVersion: "21.0.2+13"},
+		{name: "release list is read offline", releases: true, wantVersion: "21.0.2+7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := fakeAdoptium(t)
+			online := newTestRegistry(t, srv.URL, CacheDefault)
+			if err := online.FetchAvailableVersions(context.Background()); err != nil {
+				t.Fatalf("FetchAvailableVersions() error = %v", err)
+			}
+			if tt.releases {
+				if _, err := online.FindReleasesForMajor(context.Background(), 21); err != nil {
+					t.Fatalf("FindReleasesForMajor() error = %v", err)
+				}
+			}
+			srv.Close()
+
+			offline := NewRegistry()
+			offline.apiURL = srv.URL
+			offline.EnableCache(online.cachePath, time.Hour, CacheOffline)
+
+			var got string
+			if tt.releases {
+				releases, err := offline.FindReleasesForMajor(context.Background(), 21)
+				if err != nil {
+					t.Fatalf("offline FindReleasesForMajor() error = %v", err)
+				}
+				if len(releases) != 2 {
+					t.Fatalf("offline release list has %d builds, want 2", len(releases))
+				}
+				got = releases[0].Version
+			} else {
+				latest, err := offline.FindLatestForMajor(context.Background(), 21)
+				if err != nil {
+					t.Fatalf("offline FindLatestForMajor() error = %v", err)
+				}
+				got = latest.Version
+			}
+			if got != tt.wantVersion {
+				t.Errorf("offline lookup found %s, want %s", got, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestLoadCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mode    CacheMode
+		want    int
+		wantErr bool
+	}{
+		{name: "valid cache", content: `{"fetched_at": "2026-01-01T00:00:00Z", "responses": {"u": {"body": [1]}}}`, want: 1},
+		{name: "corrupt cache is treated as empty", content: `{"responses":`, want: 0},
+		{name: "missing cache is treated as empty", want: 0},
+		{name: "offline needs a cache", mode: CacheOffline, wantErr: true},
+		{name: "offline rejects a corrupt cache", content: `not json`, mode: CacheOffline, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRegistry(t, "http://127.0.0.1:0", tt.mode)
+			if tt.content != "" {
+				if err := os.WriteFile(r.cachePath, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := r.loadCache()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadCache() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(r.cache.Responses) != tt.want {
+				t.Errorf("loaded %d responses, want %d", len(r.cache.Responses), tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveCacheLeavesNoTempFiles(t *testing.T) {
+	r := newTestRegistry(t, "http://127.0.0.1:0", CacheDefault)
+	c := &catalogueCache{FetchedAt: time.Now().UTC(), Responses: map[string]cachedResponse{"u": {Body: json.RawMessage(`[1]`)}}}
+
+	for i := 0; i < 3; i++ {
+		if err := r.saveCache(c); err != nil {
+			t.Fatalf("saveCache() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(r.cachePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", e.Name())
+		}
+	}
+	if err := CheckCache(r.cachePath); err != nil {
+		t.Errorf("CheckCache() error = %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package registry
+
+import "fmt"
+
+// NotFoundError is returned when no available version matches a request
+type NotFoundError struct {
+	Version string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("version %s not found", e.Version)
+}
+
+// NetworkError is returned when the version catalogue cannot be fetched, either
+// because the request failed or because the API answered with an error status
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("cannot reach %s: %v", e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,29 +10,63 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// adoptiumAPI is the base URL of the Adoptium API
+	adoptiumAPI = "https://api.adoptium.net/v3"
+
+	// requestTimeout bounds each individual API request
+	requestTimeout = 20 * time.Second
+
+	// fetchTimeout bounds fetching the whole catalogue
+	fetchTimeout = 90 * time.Second
+
+	// fetchWorkers is the number of major versions fetched concurrently
+	fetchWorkers = 6
 )
 
 // JavaVersion represents a Java version available for download
 type JavaVersion struct {
-	Version      string
-	MajorVersion int
-	Distribution string
-	OS           string
-	Arch         string
-	DownloadURL  string
-	Checksum     string
-	FileName     string
+	Version      string `json:"version"`
+	MajorVersion int    `json:"major_version"`
+	Distribution string `json:"distribution"`
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	DownloadURL  string `json:"download_url"`
+	Checksum     string `json:"checksum"`
+	FileName     string `json:"file_name"`
+	// Size is the archive size in bytes, or 0 if the catalogue doesn't say
+	Size int64 `json:"size,omitempty"`
 }
 
 // Registry manages available Java versions
 type Registry struct {
 	versions []JavaVersion
+	// builds holds the versions of every platform, not just the current one
+	builds []JavaVersion
+	client *http.Client
+	// apiURL is the base URL of the Adoptium API
+	apiURL string
+
+	cachePath string
+	cacheTTL  time.Duration
+	cacheMode CacheMode
+	cache     *catalogueCache
+
+	// fetched collects the responses of the current fetch for the cache
+	mu      sync.Mutex
+	fetched map[string]cachedResponse
 }
 
 // NewRegistry creates a new registry instance
 func NewRegistry() *Registry {
 	return &Registry{
 		versions: []JavaVersion{},
+		client:   &http.Client{Timeout: requestTimeout},
+		apiURL:   adoptiumAPI,
 	}
 }
 
@@ -50,6 +85,7 @@ type AdoptiumRelease struct {
 			Name     string `json:"name"`
 			Link     string `json:"link"`
 			Checksum string `json:"checksum"`
+			Size     int64  `json:"size"`
 		} `json:"package"`
 	} `json:"binary"`
 	Version struct {
@@ -60,47 +96,189 @@ type AdoptiumRelease struct {
 	} `json:"version"`
 }
 
-// FetchAvailableVersions fetches available Java versions from Adoptium API
-func (r *Registry) FetchAvailableVersions() error {
+// FetchAvailableVersions fetches available Java versions from Adoptium API.
+// Major versions are fetched concurrently; cancelling ctx aborts all requests.
+func (r *Registry) FetchAvailableVersions(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	if err := r.loadCache(); err != nil {
+		return err
+	}
+	fresh := r.cacheIsFresh()
+	r.fetched = map[string]cachedResponse{}
+
 	// First, get the list of all available versions from Adoptium
-	availableVersions, err := r.fetchAvailableVersionsList()
+	availableVersions, err := r.fetchAvailableVersionsList(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch available versions list: %w", err)
 	}
 
-	// Fetch each available version
-	for _, majorVersion := range availableVersions {
-		if err := r.fetchVersionFromAdoptium(majorVersion); err != nil {
+	// Fetch each available version with a bounded pool of workers. Results are
+	// stored by index so the catalogue order doesn't depend on timing.
+	results := make([][]JavaVersion, len(availableVersions))
+	errs := make([]error, len(availableVersions))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < fetchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx], errs[idx] = r.fetchVersionFromAdoptium(ctx, availableVersions[idx])
+			}
+		}()
+	}
+
+	for idx := range availableVersions {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("fetching versions aborted: %w", err)
+	}
+
+	// A catalogue missing every version is a failed fetch, not an empty result
+	if len(errs) > 0 && allErrors(errs) {
+		return fmt.Errorf("failed to fetch versions: %w", errs[0])
+	}
+
+	// Start over, so fetching again doesn't list builds twice
+	r.builds, r.versions = nil, nil
+	for idx, majorVersion := range availableVersions {
+		if errs[idx] != nil {
 			// Log error but continue with other versions
-			fmt.Printf("Warning: Failed to fetch Java %d: %v\n", majorVersion, err)
+			fmt.Printf("Warning: Failed to fetch Java %d: %v\n", majorVersion, errs[idx])
+			continue
+		}
+		r.builds = append(r.builds, results[idx]...)
+	}
+
+	targetOS, targetArch := Platform()
+	for _, v := range r.builds {
+		if v.OS == targetOS && v.Arch == targetArch {
+			r.versions = append(r.versions, v)
 		}
 	}
 
 	// Sort versions by major version (descending)
-	sort.Slice(r.versions, func(i, j int) bool {
+	sort.SliceStable(r.versions, func(i, j int) bool {
 		return r.versions[i].MajorVersion > r.versions[j].MajorVersion
 	})
 
+	// Only a complete network fetch replaces the cache
+	if !fresh && !hasErrors(errs) {
+		c := &catalogueCache{FetchedAt: time.Now().UTC(), Responses: map[string]cachedResponse{}}
+		r.mu.Lock()
+		for url, resp := range r.fetched {
+			c.Responses[url] = resp
+		}
+		r.mu.Unlock()
+
+		// Release lists are not part of the catalogue; keep them until they
+		// are fetched again
+		for url, resp := range r.cache.Responses {
+			if _, ok := c.Responses[url]; !ok && isReleaseList(url) {
+				c.Responses[url] = resp
+			}
+		}
+
+		if err := r.saveCache(c); err != nil {
+			fmt.Printf("Warning: Failed to cache version catalogue: %v\n", err)
+		} else {
+			r.cache = c
+		}
+	}
+
 	return nil
 }
 
-// fetchAvailableVersionsList fetches the list of available Java versions from Adoptium
-func (r *Registry) fetchAvailableVersionsList() ([]int, error) {
-	url := "https://api.adoptium.net/v3/info/available_releases"
+// hasErrors reports whether any of errs is non-nil
+func hasErrors(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// allErrors reports whether every entry of errs is non-nil
+func allErrors(errs []error) bool {
+	for _, err := range errs {
+		if err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// get returns the response body for an API URL. Fresh cached responses are
+// used as-is; otherwise the request is revalidated with the cached ETag.
+func (r *Registry) get(ctx context.Context, url string) ([]byte, error) {
+	cached, haveCached := r.cache.Responses[url]
 
-	resp, err := http.Get(url)
+	if r.cacheIsFresh() {
+		if haveCached {
+			return cached.Body, nil
+		}
+		// A fresh cache may still lack responses outside the catalogue, such
+		// as release lists; only offline mode has nowhere else to look
+		if r.cacheMode == CacheOffline {
+			return nil, fmt.Errorf("%s is not in the cached catalogue", url)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch available releases: %w", err)
+		return nil, err
+	}
+	if haveCached && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, &NetworkError{URL: url, Err: err}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	var body []byte
+	etag := resp.Header.Get("ETag")
+	switch {
+	case resp.StatusCode == http.StatusNotModified && haveCached:
+		body = cached.Body
+		// A 304 need not repeat the ETag; the cached one is still valid then
+		if etag == "" {
+			etag = cached.ETag
+		}
+	case resp.StatusCode == http.StatusOK:
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, &NetworkError{URL: url, Err: fmt.Errorf("failed to read response: %w", err)}
+		}
+		if !json.Valid(body) {
+			return nil, fmt.Errorf("API returned invalid JSON")
+		}
+	default:
+		return nil, &NetworkError{URL: url, Err: fmt.Errorf("API returned status %d", resp.StatusCode)}
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	r.mu.Lock()
+	r.fetched[url] = cachedResponse{ETag: etag, Body: body}
+	r.mu.Unlock()
+
+	return body, nil
+}
+
+// fetchAvailableVersionsList fetches the list of available Java versions from Adoptium
+func (r *Registry) fetchAvailableVersionsList(ctx context.Context) ([]int, error) {
+	body, err := r.get(ctx, r.apiURL+"/info/available_releases")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to fetch available releases: %w", err)
 	}
 
 	var releasesInfo AvailableReleasesResponse
@@ -111,31 +289,144 @@ func (r *Registry) fetchAvailableVersionsList() ([]int, error) {
 	return releasesInfo.AvailableReleases, nil
 }
 
-// fetchVersionFromAdoptium fetches a specific version from Adoptium API
-func (r *Registry) fetchVersionFromAdoptium(majorVersion int) error {
-	url := fmt.Sprintf("https://api.adoptium.net/v3/assets/latest/%d/hotspot", majorVersion)
-
-	resp, err := http.Get(url)
+// fetchVersionFromAdoptium fetches the builds of a specific major version from Adoptium API
+func (r *Registry) fetchVersionFromAdoptium(ctx context.Context, majorVersion int) ([]JavaVersion, error) {
+	body, err := r.get(ctx, fmt.Sprintf("%s/assets/latest/%d/hotspot", r.apiURL, majorVersion))
 	if err != nil {
-		return fmt.Errorf("failed to fetch from Adoptium API: %w", err)
+		return nil, fmt.Errorf("failed to fetch from Adoptium API: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	var releases []AdoptiumRelease
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Extract the JDK builds of every platform
+	var versions []JavaVersion
+	for _, release := range releases {
+		if release.Binary.ImageType != "jdk" {
+			continue
+		}
+
+		version := fmt.Sprintf("%d.%d.%d+%d",
+			release.Version.Major,
+			release.Version.Minor,
+			release.Version.Security,
+			release.Version.Build)
+
+		versions = append(versions, JavaVersion{
+			Version:      version,
+			MajorVersion: release.Version.Major,
+			Distribution: "Temurin",
+			OS:           release.Binary.OS,
+			Arch:         release.Binary.Architecture,
+			DownloadURL:  release.Binary.Package.Link,
+			Checksum:     release.Binary.Package.Checksum,
+			FileName:     release.Binary.Package.Name,
+			Size:         release.Binary.Package.Size,
+		})
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return versions, nil
+}
+
+// FeatureRelease represents one GA release of a major version from the
+// Adoptium feature_releases endpoint
+type FeatureRelease struct {
+	Binaries []struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		ImageType    string `json:"image_type"`
+		Package      struct {
+			Name     string `json:"name"`
+			Link     string `json:"link"`
+			Checksum string `json:"checksum"`
+			Size     int64  `json:"size"`
+		} `json:"package"`
+	} `json:"binaries"`
+	VersionData struct {
+		Major    int `json:"major"`
+		Minor    int `json:"minor"`
+		Security int `json:"security"`
+		Build    int `json:"build"`
+	} `json:"version_data"`
+}
+
+// releasesPageSize is how many of the newest releases of a major are listed
+const releasesPageSize = 20
+
+// isReleaseList reports whether an API URL lists the releases of a major
+func isReleaseList(url string) bool {
+	return strings.Contains(url, "/assets/feature_releases/")
+}
+
+// FindReleasesForMajor returns the recent GA builds of a major version for the
+// current platform, newest first. Unlike the catalogue, which only holds the
+// latest build of each major, it includes older patch releases.
+func (r *Registry) FindReleasesForMajor(ctx context.Context, majorVersion int) ([]JavaVersion, error) {
+	// The catalogue fetch sets up the cache the release list is read through
+	if len(r.versions) == 0 {
+		if err := r.FetchAvailableVersions(ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch versions: %w", err)
+		}
+	}
+
+	targetOS, targetArch := Platform()
+	url := fmt.Sprintf("%s/assets/feature_releases/%d/ga?image_type=jdk&jvm_impl=hotspot&os=%s&architecture=%s&page_size=%d&sort_order=DESC",
+		r.apiURL, majorVersion, targetOS, targetArch, releasesPageSize)
+
+	body, err := r.get(ctx, url)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to fetch Java %d releases: %w", majorVersion, err)
 	}
 
-	var releases []AdoptiumRelease
+	// Keep a release list fetched from the network for later runs, in
+	// particular --offline ones
+	r.mu.Lock()
+	resp, fetched := r.fetched[url]
+	r.mu.Unlock()
+	if fetched {
+		r.cache.Responses[url] = resp
+		if err := r.saveCache(r.cache); err != nil {
+			fmt.Printf("Warning: Failed to cache Java %d releases: %v\n", majorVersion, err)
+		}
+	}
+
+	var releases []FeatureRelease
 	if err := json.Unmarshal(body, &releases); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	// Determine current OS and Arch
+	var versions []JavaVersion
+	for _, release := range releases {
+		for _, binary := range release.Binaries {
+			if binary.ImageType != "jdk" || binary.OS != targetOS || binary.Architecture != targetArch {
+				continue
+			}
+
+			versions = append(versions, JavaVersion{
+				Version: fmt.Sprintf("%d.%d.%d+%d",
+					release.VersionData.Major,
+					release.VersionData.Minor,
+					release.VersionData.Security,
+					release.VersionData.Build),
+				MajorVersion: release.VersionData.Major,
+				Distribution: "Temurin",
+				OS:           binary.OS,
+				Arch:         binary.Architecture,
+				DownloadURL:  binary.Package.Link,
+				Checksum:     binary.Package.Checksum,
+				FileName:     binary.Package.Name,
+				Size:         binary.Package.Size,
+			})
+		}
+	}
+
+	return versions, nil
+}
+
+// Platform returns the current OS and architecture as named by the Adoptium API
+func Platform() (string, string) {
 	targetOS := runtime.GOOS
 	if targetOS == "darwin" {
 		targetOS = "mac"
@@ -151,33 +442,18 @@ func (r *Registry) fetchVersionFromAdoptium(majorVersion int) error {
 		targetArch = "aarch64"
 	}
 
-	// Extract binaries matching current OS/Arch
-	for _, release := range releases {
-		// Strict matching for OS and Architecture
-		if release.Binary.OS == targetOS &&
-			release.Binary.Architecture == targetArch &&
-			release.Binary.ImageType == "jdk" {
-
-			version := fmt.Sprintf("%d.%d.%d+%d",
-				release.Version.Major,
-				release.Version.Minor,
-				release.Version.Security,
-				release.Version.Build)
-
-			r.versions = append(r.versions, JavaVersion{
-				Version:      version,
-				MajorVersion: release.Version.Major,
-				Distribution: "Temurin",
-				OS:           release.Binary.OS,
-				Arch:         release.Binary.Architecture,
-				DownloadURL:  release.Binary.Package.Link,
-				Checksum:     release.Binary.Package.Checksum,
-				FileName:     release.Binary.Package.Name,
-			})
+	return targetOS, targetArch
+}
+
+// Builds returns the builds of an exact version for every platform it is published for
+func (r *Registry) Builds(version string) []JavaVersion {
+	var builds []JavaVersion
+	for _, v := range r.builds {
+		if v.Version == version {
+			builds = append(builds, v)
 		}
 	}
-
-	return nil
+	return builds
 }
 
 // GetVersions returns all available versions
@@ -194,7 +470,7 @@ func (r *Registry) FindVersion(versionStr string) (*JavaVersion, error) {
 				return &v, nil
 			}
 		}
-		return nil, fmt.Errorf("version %d not found", majorVersion)
+		return nil, &NotFoundError{Version: versionStr}
 	}
 
 	// Try to find exact match
@@ -211,7 +487,7 @@ func (r *Registry) FindVersion(versionStr string) (*JavaVersion, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("version %s not found", versionStr)
+	return nil, &NotFoundError{Version: versionStr}
 }
 
 // GetMajorVersions returns unique major versions
@@ -231,10 +507,10 @@ func (r *Registry) GetMajorVersions() []int {
 }
 
 // FindLatestForMajor finds the latest version for a specific major version
-func (r *Registry) FindLatestForMajor(majorVersion int) (*JavaVersion, error) {
+func (r *Registry) FindLatestForMajor(ctx context.Context, majorVersion int) (*JavaVersion, error) {
 	// If versions are not loaded, fetch them
 	if len(r.versions) == 0 {
-		if err := r.FetchAvailableVersions(); err != nil {
+		if err := r.FetchAvailableVersions(ctx); err != nil {
 			return nil, fmt.Errorf("failed to fetch versions: %w", err)
 		}
 	}
@@ -248,7 +524,7 @@ func (r *Registry) FindLatestForMajor(majorVersion int) (*JavaVersion, error) {
 	}
 
 	if len(candidates) == 0 {
-		return nil, fmt.Errorf("no versions found for Java %d", majorVersion)
+		return nil, &NotFoundError{Version: strconv.Itoa(majorVersion)}
 	}
 
 	// Return the first one (versions are already sorted with latest first)
@@ -0,0 +1,16 @@
+//go:build linux || darwin
+
+package selfupdate
+
+import "os"
+
+// replaceExecutable moves the new binary over the running one. On Unix the
+// rename is atomic and the running process keeps its open copy.
+func replaceExecutable(exePath, newPath string) error {
+	return os.Rename(newPath, exePath)
+}
+
+// CleanupOld removes files left behind by a previous self-update
+func CleanupOld(exePath string) {
+	// Nothing is left behind on Unix
+}
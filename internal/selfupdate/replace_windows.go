@@ -0,0 +1,33 @@
+package selfupdate
+
+import (
+	"fmt"
+	"os"
+)
+
+// replaceExecutable swaps in the new binary. Windows doesn't allow overwriting
+// a running executable, but it can be renamed; the old copy is deleted by
+// CleanupOld on the next run.
+func replaceExecutable(exePath, newPath string) error {
+	oldPath := exePath + ".old"
+	os.Remove(oldPath)
+
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return err
+	}
+
+	if err := os.Rename(newPath, exePath); err != nil {
+		// Put the original back so jvt keeps working
+		if restoreErr := os.Rename(oldPath, exePath); restoreErr != nil {
+			return fmt.Errorf("%w (restoring the old binary also failed: %v)", err, restoreErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CleanupOld removes the previous binary left behind by a self-update
+func CleanupOld(exePath string) {
+	os.Remove(exePath + ".old")
+}
@@ -0,0 +1,345 @@
+package selfupdate
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rexqwer911/jvt/internal/download"
+)
+
+// Release channels
+const (
+	// ChannelStable only considers regular releases
+	ChannelStable = "stable"
+	// ChannelPrerelease also considers releases marked as pre-releases
+	ChannelPrerelease = "prerelease"
+)
+
+const (
+	// checksumsAsset is the checksum list published with every release
+	checksumsAsset = "checksums.txt"
+
+	// requestTimeout bounds each GitHub API request
+	requestTimeout = 20 * time.Second
+
+	// smokeTestTimeout bounds running the new binary before it is installed
+	smokeTestTimeout = 30 * time.Second
+)
+
+// Release is a published jvt release with an archive for this platform
+type Release struct {
+	Version     string `json:"version"`
+	Tag         string `json:"tag"`
+	Prerelease  bool   `json:"prerelease"`
+	AssetName   string `json:"asset"`
+	AssetURL    string `json:"asset_url"`
+	ChecksumURL string `json:"checksum_url"`
+}
+
+// githubRelease is the subset of the GitHub releases API used by jvt
+type githubRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// Updater finds and installs new jvt releases
+type Updater struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewUpdater creates an updater for the GitHub API base of a repository,
+// e.g. https://api.github.com/repos/rexqwer911/jvt
+func NewUpdater(baseURL string) *Updater {
+	return &Updater{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// ValidChannel reports whether channel names a known release channel
+func ValidChannel(channel string) bool {
+	return channel == ChannelStable || channel == ChannelPrerelease
+}
+
+// AssetName returns the name of the release archive for the running platform,
+// as published by the release workflow (jvt-windows-amd64.zip, jvt-linux-amd64.zip,
+// jvt-macos-arm64.zip, ...)
+func AssetName() string {
+	osName := runtime.GOOS
+	if osName == "darwin" {
+		osName = "macos"
+	}
+	return fmt.Sprintf("jvt-%s-%s.zip", osName, runtime.GOARCH)
+}
+
+// Latest returns the newest release on the channel that ships an archive for
+// this platform together with a checksum list
+func (u *Updater) Latest(ctx context.Context, channel string) (*Release, error) {
+	url := u.baseURL + "/releases"
+	body, err := u.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []githubRelease
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	assetName := AssetName()
+	var latest *Release
+	for _, r := range releases {
+		if r.Draft || (r.Prerelease && channel != ChannelPrerelease) {
+			continue
+		}
+
+		rel := &Release{
+			Version:    strings.TrimPrefix(r.TagName, "v"),
+			Tag:        r.TagName,
+			Prerelease: r.Prerelease,
+			AssetName:  assetName,
+		}
+		for _, a := range r.Assets {
+			switch a.Name {
+			case assetName:
+				rel.AssetURL = a.URL
+			case checksumsAsset:
+				rel.ChecksumURL = a.URL
+			}
+		}
+		if rel.AssetURL == "" || rel.ChecksumURL == "" {
+			continue
+		}
+
+		if latest == nil || IsNewer(rel.Version, latest.Version) {
+			latest = rel
+		}
+	}
+
+	if latest == nil {
+		return nil, fmt.Errorf("no %s release with %s and %s found", channel, assetName, checksumsAsset)
+	}
+	return latest, nil
+}
+
+// Apply downloads a release archive, verifies it against the published
+// checksum and replaces the executable at exePath with the binary it contains
+func (u *Updater) Apply(ctx context.Context, rel *Release, exePath string) error {
+	tmpDir, err := os.MkdirTemp("", "jvt-update-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	checksums, err := u.get(ctx, rel.ChecksumURL)
+	if err != nil {
+		return err
+	}
+	checksum, err := findChecksum(checksums, rel.AssetName)
+	if err != nil {
+		return err
+	}
+
+	downloader := download.NewDownloader(tmpDir)
+	archivePath, err := downloader.DownloadAndVerify(ctx, rel.AssetURL, rel.AssetName, checksum)
+	if err != nil {
+		return err
+	}
+
+	// Stage the new binary next to the old one so the final rename stays on one filesystem
+	newPath := exePath + ".new"
+	if err := extractBinary(archivePath, newPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+
+	if fi, err := os.Stat(exePath); err == nil {
+		os.Chmod(newPath, fi.Mode().Perm())
+	}
+
+	if err := smokeTest(newPath, rel.Version); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+
+	if err := replaceExecutable(exePath, newPath); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("failed to replace %s: %w", exePath, err)
+	}
+
+	return nil
+}
+
+// get fetches a URL from the release server
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "jvt-self-update")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, &download.NetworkError{URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &download.NetworkError{URL: url, Err: fmt.Errorf("server returned status %d", resp.StatusCode)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &download.NetworkError{URL: url, Err: err}
+	}
+	return body, nil
+}
+
+// findChecksum looks up the SHA256 of an asset in a checksum list. Both the
+// "name: HASH" lines of the release workflow and sha256sum's "HASH  name" are accepted.
+func findChecksum(checksums []byte, assetName string) (string, error) {
+	checksums = bytes.TrimPrefix(checksums, []byte("\xef\xbb\xbf")) // PowerShell writes a BOM
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if name, hash, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) == assetName {
+			return strings.ToLower(strings.TrimSpace(hash)), nil
+		}
+		if fields := strings.Fields(line); len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s has no checksum for %s", checksumsAsset, assetName)
+}
+
+// extractBinary writes the jvt executable contained in a release archive to destPath
+func extractBinary(archivePath, destPath string) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(archivePath), err)
+	}
+	defer r.Close()
+
+	binaryName := "jvt"
+	if runtime.GOOS == "windows" {
+		binaryName = "jvt.exe"
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || filepath.Base(f.Name) != binaryName {
+			continue
+		}
+
+		src, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			dst.Close()
+			return err
+		}
+		return dst.Close()
+	}
+
+	return fmt.Errorf("%s does not contain %s", filepath.Base(archivePath), binaryName)
+}
+
+// smokeTest runs `<binary> --version` and checks that it reports the release version
+func smokeTest(binary, version string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), smokeTestTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, binary, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("the downloaded jvt does not run on this system: %w", err)
+	}
+	// cobra prints "jvt version <version>"
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 || strings.TrimPrefix(fields[len(fields)-1], "v") != strings.TrimPrefix(version, "v") {
+		return fmt.Errorf("the downloaded jvt reports %q, expected version %s", strings.TrimSpace(string(output)), version)
+	}
+	return nil
+}
+
+// IsReleaseVersion reports whether v is a release version: dotted numbers with
+// an optional "v" prefix and "-prerelease" suffix. Development builds report
+// "dev" or another string that is not.
+func IsReleaseVersion(v string) bool {
+	core, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	for _, part := range strings.Split(core, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// IsNewer reports whether version a is newer than version b. Versions are
+// dotted numbers with an optional "-prerelease" suffix, which sorts before the
+// plain version. A version that is not a release version is never newer or
+// older, so it is never replaced by mistake.
+func IsNewer(a, b string) bool {
+	if !IsReleaseVersion(a) || !IsReleaseVersion(b) {
+		return false
+	}
+
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for idx := 0; idx < len(aParts) || idx < len(bParts); idx++ {
+		an, bn := versionPart(aParts, idx), versionPart(bParts, idx)
+		if an != bn {
+			return an > bn
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return false
+	case aPre == "":
+		return true
+	case bPre == "":
+		return false
+	default:
+		return aPre > bPre
+	}
+}
+
+// versionPart returns the numeric component at idx, or 0 if it is missing
+func versionPart(parts []string, idx int) int {
+	if idx >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[idx])
+	return n
+}
@@ -0,0 +1,92 @@
+package selfupdate
+
+import "testing"
+
+func TestFindChecksum(t *testing.T) {
+	// checksums.txt as written by the release workflow, with PowerShell's BOM
+	release := "\xef\xbb\xbfSHA256 Checksums:\r\n\r\n" +
+		"jvt-windows-amd64.zip: 0A1B2C\r\n" +
+		"jvt.exe: 3D4E5F\r\n" +
+		"jvt-linux-amd64.zip: ABCDEF\r\n"
+
+	tests := []struct {
+		name      string
+		checksums string
+		asset     string
+		want      string
+		wantErr   bool
+	}{
+		{name: "release workflow format", checksums: release, asset: "jvt-windows-amd64.zip", want: "0a1b2c"},
+		{name: "release workflow later entry", checksums: release, asset: "jvt-linux-amd64.zip", want: "abcdef"},
+		{name: "sha256sum format", checksums: "abcdef  jvt-macos-arm64.zip\n", asset: "jvt-macos-arm64.zip", want: "abcdef"},
+		{name: "sha256sum binary mode", checksums: "ABCDEF *jvt-macos-arm64.zip\n", asset: "jvt-macos-arm64.zip", want: "abcdef"},
+		{name: "name is matched exactly", checksums: "jvt-windows-amd64.zip.sig: 123456\n", asset: "jvt-windows-amd64.zip", wantErr: true},
+		{name: "missing asset", checksums: release, asset: "jvt-macos-arm64.zip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findChecksum([]byte(tt.checksums), tt.asset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("findChecksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "1.4.0", b: "1.3.0", want: true},
+		{a: "1.3.0", b: "1.4.0", want: false},
+		{a: "1.3.0", b: "1.3.0", want: false},
+		{a: "v1.10.0", b: "1.9.0", want: true},
+		{a: "2.0", b: "1.99.99", want: true},
+		{a: "1.3", b: "1.3.0", want: false},
+		{a: "1.3.1", b: "1.3", want: true},
+		{a: "1.4.0", b: "1.4.0-rc.1", want: true},
+		{a: "1.4.0-rc.1", b: "1.4.0", want: false},
+		{a: "1.4.0-rc.2", b: "1.4.0-rc.1", want: true},
+		{a: "1.4.0-rc.1", b: "1.3.0", want: true},
+		{a: "dev", b: "1.3.0", want: false},
+		{a: "1.3.0", b: "dev", want: false},
+		{a: "1.3.0", b: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := IsNewer(tt.a, tt.b); got != tt.want {
+				t.Errorf("IsNewer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsReleaseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "1.3.0", want: true},
+		{version: "v1.3.0", want: true},
+		{version: "1.4.0-rc.1", want: true},
+		{version: "2", want: true},
+		{version: "dev", want: false},
+		{version: "", want: false},
+		{version: "1.3.x", want: false},
+		{version: "v", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := IsReleaseVersion(tt.version); got != tt.want {
+				t.Errorf("IsReleaseVersion(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
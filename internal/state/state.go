@@ -0,0 +1,89 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rexqwer911/jvt/internal/lock"
+)
+
+// State holds jvt's persistent per-user state
+type State struct {
+	// Default is the version last selected with `jvt use`
+	Default string `json:"default,omitempty"`
+
+	// UpdateChannel is the release channel pinned with `jvt self-update --channel`
+	UpdateChannel string `json:"update_channel,omitempty"`
+
+	// Aliases maps names set with `jvt alias set` to version specs
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Pinned lists the builds protected with `jvt pin`, sorted
+	Pinned []string `json:"pinned,omitempty"`
+}
+
+// Load reads the state file. A missing file yields an empty state.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state %s: %w", path, err)
+	}
+
+	return &s, nil
+}
+
+// Update loads the state file, applies fn and writes the result back while
+// holding a cross-process lock, so concurrent jvt processes don't lose updates
+func Update(ctx context.Context, path string, fn func(s *State) error) error {
+	l, err := lock.Acquire(ctx, path+".lock", "jvt state", lock.DefaultTimeout)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(s); err != nil {
+		return err
+	}
+
+	return s.save(path)
+}
+
+// save writes the state file via a temporary file and rename
+func (s *State) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,71 +18,33 @@ func (m *Manager) SetEnvironment(version string) error {
 }
 
 // SetUserEnvironment sets JAVA_HOME and PATH in user environment variables (persistent)
-func (m *Manager) SetUserEnvironment(version string) error {
-	javaHome := filepath.Join(m.installDir, version)
-
-	// Update shell config files
-	home, err := os.UserHomeDir()
+func (m *Manager) SetUserEnvironment(ctx context.Context, version string) error {
+	l, err := m.lockState(ctx)
 	if err != nil {
 		return err
 	}
+	defer l.Release()
 
-	rcFiles := []string{".zshrc", ".bashrc", ".profile", ".bash_profile"}
-	updated := false
-
-	// Ensure ~/.jvt/jvt.sh exists and is sourced
-	jvtScriptPath := filepath.Join(home, ".jvt", "jvt.sh")
-	jvtScriptContent := fmt.Sprintf("export JAVA_HOME=\"%s\"\nexport PATH=\"$JAVA_HOME/bin:$PATH\"\n", javaHome)
-
-	if err := os.MkdirAll(filepath.Dir(jvtScriptPath), 0755); err != nil {
+	if err := m.writeActivationScript(version); err != nil {
 		return err
 	}
-	if err := os.WriteFile(jvtScriptPath, []byte(jvtScriptContent), 0644); err != nil {
+
+	// Update shell config files
+	home, err := os.UserHomeDir()
+	if err != nil {
 		return err
 	}
 
-	for _, rc := range rcFiles {
-		rcPath := filepath.Join(home, rc)
+	updated := false
+	for _, rcPath := range ShellRCFiles(home) {
 		if _, err := os.Stat(rcPath); err == nil {
-			// File exists, check if it sources jvt.sh AND has the wrapper
+			// File exists; add the block unless it already has one. Blocks
+			// the user has edited are left as they are.
 			content, err := os.ReadFile(rcPath)
 			if err == nil {
-				strContent := string(content)
-				// Check if we need to update:
-				// 1. Missing the basic source line?
-				// 2. Missing the function wrapper?
-				needsUpdate := !strings.Contains(strContent, ".jvt/jvt.sh") || !strings.Contains(strContent, "jvt() {")
-
-				if needsUpdate {
-					// Append source command and wrapper
-					f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_WRONLY, 0644)
-					if err == nil {
-						if !strings.HasSuffix(strContent, "\n") {
-							f.WriteString("\n")
-						}
-
-						// If the file already has the source line but not the wrapper, we might be duplicating the source line
-						// inside the script block. That's acceptable (idempotent-ish).
-						// To be cleaner, we could check, but appending the whole block is safer ensuring consistency.
-
-						// Inject shell function wrapper to allow auto-sourcing
-						script := `
-# JVT Java Version Tool
-export JVT_HOME="$HOME/.jvt"
-[ -s "$JVT_HOME/jvt.sh" ] && . "$JVT_HOME/jvt.sh"
-
-jvt() {
-    command jvt "$@"
-    local exit_code=$?
-    if [ $exit_code -eq 0 ] && [ "$1" = "use" ]; then
-        [ -s "$HOME/.jvt/jvt.sh" ] && . "$HOME/.jvt/jvt.sh"
-    fi
-    return $exit_code
-}
-`
-						f.WriteString(script)
-						f.Close()
-						fmt.Printf("Updated %s with shell wrapper\n", rc)
+				if !HasShellIntegration(string(content)) {
+					if err := AddShellIntegration(rcPath); err == nil {
+						fmt.Printf("Updated %s with shell wrapper\n", filepath.Base(rcPath))
 						updated = true
 					}
 				} else {
@@ -94,7 +57,21 @@ jvt() {
 	if !updated {
 		fmt.Println("Could not find common shell configuration files (.zshrc, .bashrc, etc.).")
 		fmt.Println("Please add the following execution to your shell startup script:")
-		fmt.Printf(`
+		fmt.Print(shellIntegration)
+	}
+
+	if err := m.recordDefault(ctx, version); err != nil {
+		return err
+	}
+
+	fmt.Printf("Java %s configured.\n", version)
+	return nil
+}
+
+// shellIntegration is appended to shell startup files. It sources jvt.sh and
+// wraps jvt so that `jvt use` takes effect in the running shell.
+const shellIntegration = `
+# JVT Java Version Tool
 export JVT_HOME="$HOME/.jvt"
 [ -s "$JVT_HOME/jvt.sh" ] && . "$JVT_HOME/jvt.sh"
 
@@ -106,18 +83,152 @@ jvt() {
     fi
     return $exit_code
 }
-`)
+`
+
+// ShellRCFiles returns the shell startup files jvt integrates with
+func ShellRCFiles(home string) []string {
+	rcFiles := []string{".zshrc", ".bashrc", ".profile", ".bash_profile"}
+	paths := make([]string, len(rcFiles))
+	for idx, rc := range rcFiles {
+		paths[idx] = filepath.Join(home, rc)
 	}
+	return paths
+}
 
-	fmt.Printf("Java %s configured.\n", version)
-	return nil
+// HasShellIntegration reports whether a shell startup file contains a jvt
+// integration block, intact or not
+func HasShellIntegration(content string) bool {
+	return ShellIntegrationCount(content) > 0
+}
+
+// ShellIntegrationCount returns how many jvt integration blocks a shell startup
+// file contains. A block is recognised by its wrapper function, so edited
+// blocks are counted too.
+func ShellIntegrationCount(content string) int {
+	return strings.Count(content, "jvt() {")
+}
+
+// ShellIntegrationModified reports whether a shell startup file has a jvt
+// integration block that differs from the block jvt writes. jvt leaves such
+// blocks to the user rather than adding or removing blocks around them.
+func ShellIntegrationModified(content string) bool {
+	return ShellIntegrationCount(content) > strings.Count(content, shellIntegration)
+}
+
+// AddShellIntegration appends the jvt integration block to a shell startup file
+func AddShellIntegration(rcPath string) error {
+	content, err := os.ReadFile(rcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		if _, err := f.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := f.WriteString(shellIntegration); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// RemoveDuplicateShellIntegration keeps the first jvt integration block of a
+// shell startup file and removes later identical copies
+func RemoveDuplicateShellIntegration(rcPath string) error {
+	content, err := os.ReadFile(rcPath)
+	if err != nil {
+		return err
+	}
+
+	text := string(content)
+	first := strings.Index(text, shellIntegration)
+	if first < 0 {
+		return fmt.Errorf("%s contains modified jvt blocks; remove the extra copies manually", rcPath)
+	}
+
+	head := text[:first+len(shellIntegration)]
+	tail := strings.ReplaceAll(text[first+len(shellIntegration):], shellIntegration, "")
+	if ShellIntegrationCount(head+tail) > 1 {
+		return fmt.Errorf("%s contains modified jvt blocks; remove the extra copies manually", rcPath)
+	}
+
+	return os.WriteFile(rcPath, []byte(head+tail), 0644)
+}
+
+// ActivationScriptPath returns the location of jvt.sh, which exports the
+// JAVA_HOME and PATH of the default version
+func ActivationScriptPath(home string) string {
+	return filepath.Join(home, ".jvt", "jvt.sh")
+}
+
+// ActivationScriptJavaHome returns the JAVA_HOME exported by jvt.sh
+func ActivationScriptJavaHome(home string) (string, error) {
+	content, err := os.ReadFile(ActivationScriptPath(home))
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if value, ok := strings.CutPrefix(line, "export JAVA_HOME="); ok {
+			return strings.Trim(value, `"`), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not export JAVA_HOME", ActivationScriptPath(home))
+}
+
+// writeActivationScript points jvt.sh at an installed version
+func (m *Manager) writeActivationScript(version string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	javaHome := m.javaHome(version)
+	jvtScriptPath := ActivationScriptPath(home)
+	jvtScriptContent := fmt.Sprintf("export JAVA_HOME=\"%s\"\nexport PATH=\"$JAVA_HOME/bin:$PATH\"\n", javaHome)
+
+	if err := os.MkdirAll(filepath.Dir(jvtScriptPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(jvtScriptPath, []byte(jvtScriptContent), 0644)
+}
+
+// RefreshActivationScript rewrites jvt.sh for the recorded default version
+func (m *Manager) RefreshActivationScript(ctx context.Context) error {
+	l, err := m.lockState(ctx)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	def, err := m.GetDefaultVersion()
+	if err != nil {
+		return err
+	}
+	if def == "" {
+		return fmt.Errorf("no default version recorded; run 'jvt use <version>'")
+	}
+	return m.writeActivationScript(def)
 }
 
 // SetSystemEnvironment sets JAVA_HOME and PATH in SYSTEM environment variables (persistent)
-func (m *Manager) SetSystemEnvironment(version string) error {
+func (m *Manager) SetSystemEnvironment(ctx context.Context, version string) error {
 	return fmt.Errorf("system-wide configuration not supported on Unix yet (requires sudo)")
 }
 
+// SystemJavaPaths returns Java entries of a system-wide PATH that override
+// the user's. Unix has no such PATH; conflicts show up on the shell PATH instead.
+func SystemJavaPaths() ([]string, error) {
+	return nil, nil
+}
+
 // checkSystemJava checks if there's a system-level Java installation
 func (m *Manager) checkSystemJava() {
 	// Not implemented for Unix yet
@@ -1,6 +1,7 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,7 +13,7 @@ import (
 // SetEnvironment sets JAVA_HOME and updates PATH for the current session (Windows specific logic if needed, but os.Setenv is generic)
 // However, useless for parent shell.
 func (m *Manager) SetEnvironment(version string) error {
-	javaHome := filepath.Join(m.installDir, version)
+	javaHome := m.javaHome(version)
 	javaBin := filepath.Join(javaHome, "bin")
 
 	if err := os.Setenv("JAVA_HOME", javaHome); err != nil {
@@ -30,8 +31,14 @@ func (m *Manager) SetEnvironment(version string) error {
 }
 
 // SetUserEnvironment sets JAVA_HOME and PATH in user environment variables (persistent)
-func (m *Manager) SetUserEnvironment(version string) error {
-	javaHome := filepath.Join(m.installDir, version)
+func (m *Manager) SetUserEnvironment(ctx context.Context, version string) error {
+	l, err := m.lockState(ctx)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	javaHome := m.javaHome(version)
 	javaBin := filepath.Join(javaHome, "bin")
 
 	key, err := registry.OpenKey(registry.CURRENT_USER, `Environment`, registry.ALL_ACCESS)
@@ -55,13 +62,23 @@ func (m *Manager) SetUserEnvironment(version string) error {
 		return fmt.Errorf("failed to set PATH: %w", err)
 	}
 
+	if err := m.recordDefault(ctx, version); err != nil {
+		return err
+	}
+
 	m.checkSystemJava()
 	return nil
 }
 
 // SetSystemEnvironment sets JAVA_HOME and PATH in SYSTEM environment variables (persistent)
-func (m *Manager) SetSystemEnvironment(version string) error {
-	javaHome := filepath.Join(m.installDir, version)
+func (m *Manager) SetSystemEnvironment(ctx context.Context, version string) error {
+	l, err := m.lockState(ctx)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	javaHome := m.javaHome(version)
 	javaBin := filepath.Join(javaHome, "bin")
 
 	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`, registry.ALL_ACCESS)
@@ -88,35 +105,79 @@ func (m *Manager) SetSystemEnvironment(version string) error {
 	return nil
 }
 
-// checkSystemJava checks if there's a system-level Java installation
-func (m *Manager) checkSystemJava() {
+// SystemJavaPaths returns the Java-related entries of the SYSTEM PATH. They
+// come before the user PATH and may override the jvt-managed version.
+func SystemJavaPaths() ([]string, error) {
 	key, err := registry.OpenKey(registry.LOCAL_MACHINE,
 		`SYSTEM\CurrentControlSet\Control\Session Manager\Environment`,
 		registry.QUERY_VALUE)
 	if err != nil {
-		return
+		return nil, fmt.Errorf("failed to open registry: %w", err)
 	}
 	defer key.Close()
 
 	systemPath, _, err := key.GetStringValue("Path")
 	if err != nil {
-		return
+		if err == registry.ErrNotExist {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read PATH: %w", err)
 	}
 
-	pathParts := strings.Split(systemPath, ";")
 	var systemJavaPaths []string
-
-	for _, part := range pathParts {
-		partLower := strings.ToLower(part)
-		if strings.Contains(partLower, "java") ||
-			strings.Contains(partLower, "jdk") ||
-			strings.Contains(partLower, "jre") ||
-			strings.Contains(partLower, "adoptium") ||
-			strings.Contains(partLower, "temurin") {
+	for _, part := range strings.Split(systemPath, ";") {
+		if isJavaPath(part) {
 			systemJavaPaths = append(systemJavaPaths, part)
 		}
 	}
 
+	return systemJavaPaths, nil
+}
+
+// UserJavaHome returns the JAVA_HOME stored in the user environment
+func UserJavaHome() (string, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Environment`, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed to open registry: %w", err)
+	}
+	defer key.Close()
+
+	javaHome, _, err := key.GetStringValue("JAVA_HOME")
+	if err != nil && err != registry.ErrNotExist {
+		return "", fmt.Errorf("failed to read JAVA_HOME: %w", err)
+	}
+	return javaHome, nil
+}
+
+// RefreshUserEnvironment points the user environment at the recorded default version
+func (m *Manager) RefreshUserEnvironment(ctx context.Context) error {
+	def, err := m.GetDefaultVersion()
+	if err != nil {
+		return err
+	}
+	if def == "" {
+		return fmt.Errorf("no default version recorded; run 'jvt use <version>'")
+	}
+	return m.SetUserEnvironment(ctx, def)
+}
+
+// isJavaPath reports whether a PATH entry looks like a Java installation
+func isJavaPath(part string) bool {
+	partLower := strings.ToLower(part)
+	return strings.Contains(partLower, "java") ||
+		strings.Contains(partLower, "jdk") ||
+		strings.Contains(partLower, "jre") ||
+		strings.Contains(partLower, "adoptium") ||
+		strings.Contains(partLower, "temurin")
+}
+
+// checkSystemJava checks if there's a system-level Java installation
+func (m *Manager) checkSystemJava() {
+	systemJavaPaths, err := SystemJavaPaths()
+	if err != nil {
+		return
+	}
+
 	if len(systemJavaPaths) > 0 {
 		fmt.Println("\nWARNING: System-level Java installation detected!")
 		fmt.Println("The following Java paths are in SYSTEM PATH (requires admin to remove):")
@@ -124,6 +185,7 @@ func (m *Manager) checkSystemJava() {
 			fmt.Printf("  - %s\n", p)
 		}
 		fmt.Println("\nThese may override your jvt-managed Java version.")
+		fmt.Println("Run 'jvt doctor' for details.")
 	}
 }
 
@@ -146,13 +208,9 @@ func (m *Manager) updatePathString(currentPath, javaBin string) string {
 		if partLower == cleanJavaBin {
 			continue
 		}
-		if strings.Contains(partLower, "java") ||
-			strings.Contains(partLower, "jdk") ||
-			strings.Contains(partLower, "jre") ||
-			strings.Contains(partLower, "adoptium") ||
-			strings.Contains(partLower, "temurin") {
+		if isJavaPath(part) {
 			continue
-		} // Logic simplified for brevity in this thought, but should copy original logic or use shared helper
+		}
 
 		newPathParts = append(newPathParts, part)
 	}
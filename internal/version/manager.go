@@ -1,22 +1,30 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/rexqwer911/jvt/internal/install"
+	"github.com/rexqwer911/jvt/internal/lock"
+	"github.com/rexqwer911/jvt/internal/state"
 )
 
 // Manager handles Java version switching
 type Manager struct {
 	installDir string
+	stateFile  string
 }
 
-// NewManager creates a new version manager
-func NewManager(installDir string) *Manager {
+// NewManager creates a new version manager over the versions in installDir
+// that records the default version in stateFile
+func NewManager(installDir, stateFile string) *Manager {
 	return &Manager{
 		installDir: installDir,
+		stateFile:  stateFile,
 	}
 }
 
@@ -36,10 +44,46 @@ func (m *Manager) GetCurrentVersion() (string, error) {
 		return "", fmt.Errorf("current Java is not managed by jvt")
 	}
 
-	version := filepath.Base(javaHome)
+	// The version is the first path component below the install directory
+	// (JAVA_HOME may point deeper, e.g. into Contents/Home on macOS)
+	rel, err := filepath.Rel(m.installDir, javaHome)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("current Java is not managed by jvt")
+	}
+
+	version := strings.Split(filepath.ToSlash(rel), "/")[0]
 	return version, nil
 }
 
+// lockState takes the cross-process lock guarding the active-version state
+// (jvt.sh and shell integration on Unix, environment variables on Windows)
+func (m *Manager) lockState(ctx context.Context) (*lock.Lock, error) {
+	return lock.Acquire(ctx, filepath.Join(filepath.Dir(m.installDir), "state.lock"), "the active Java version", lock.DefaultTimeout)
+}
+
+// recordDefault remembers the version selected as the user default
+func (m *Manager) recordDefault(ctx context.Context, version string) error {
+	return state.Update(ctx, m.stateFile, func(s *state.State) error {
+		s.Default = version
+		return nil
+	})
+}
+
+// GetDefaultVersion returns the version last selected with `jvt use`, or an
+// empty string if none has been recorded
+func (m *Manager) GetDefaultVersion() (string, error) {
+	s, err := state.Load(m.stateFile)
+	if err != nil {
+		return "", err
+	}
+	return s.Default, nil
+}
+
+// javaHome returns the JAVA_HOME path for an installed version
+func (m *Manager) javaHome(version string) string {
+	return install.NewInstaller(m.installDir).GetJavaHome(version)
+}
+
 // IsVersionActive checks if the specified version is currently active
 func (m *Manager) IsVersionActive(version string) (bool, error) {
 	currentVersion, err := m.GetCurrentVersion()
@@ -77,6 +121,22 @@ func CompareVersions(v1, v2 string) (int, error) {
 	return 0, nil
 }
 
+// SameMinor reports whether two versions belong to the same feature and minor
+// release, e.g. 17.0.9+9 and 17.0.10+7, so one is a patch update of the other
+func SameMinor(v1, v2 string) (bool, error) {
+	parts1, err := parseVersion(v1)
+	if err != nil {
+		return false, fmt.Errorf("invalid version v1: %w", err)
+	}
+
+	parts2, err := parseVersion(v2)
+	if err != nil {
+		return false, fmt.Errorf("invalid version v2: %w", err)
+	}
+
+	return parts1[0] == parts2[0] && parts1[1] == parts2[1], nil
+}
+
 // parseVersion parses a version string into [major, minor, patch, build]
 func parseVersion(version string) ([4]int, error) {
 	var parts [4]int
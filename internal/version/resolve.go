@@ -0,0 +1,146 @@
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AmbiguousVersionError is returned when a version spec matches more than one
+// installed version and jvt cannot safely pick one
+type AmbiguousVersionError struct {
+	Spec       string
+	Candidates []string
+}
+
+func (e *AmbiguousVersionError) Error() string {
+	return fmt.Sprintf("version %s is ambiguous, it matches: %s", e.Spec, strings.Join(e.Candidates, ", "))
+}
+
+// NotInstalledError is returned when no installed version matches a version spec
+type NotInstalledError struct {
+	Spec string
+}
+
+func (e *NotInstalledError) Error() string {
+	return fmt.Sprintf("version %s is not installed", e.Spec)
+}
+
+// Resolver matches user-typed version specs against installed versions
+type Resolver struct {
+	installed []string
+}
+
+// NewResolver creates a resolver over the given installed versions
+func NewResolver(installed []string) *Resolver {
+	return &Resolver{
+		installed: installed,
+	}
+}
+
+// Matches returns the installed versions matching spec, newest first. An
+// installed version whose name equals spec is the only match. Otherwise spec is
+// compared numerically component by component, so "1" matches 1.x but never 11
+// or 17, and "17.0" matches 17.0.x but not 17.1.x.
+func (r *Resolver) Matches(spec string) []string {
+	for _, v := range r.installed {
+		if v == spec {
+			return []string{v}
+		}
+	}
+
+	want, count, build, err := parseSpec(spec)
+	if err != nil {
+		return nil
+	}
+
+	var matches []string
+	for _, v := range r.installed {
+		parts, err := parseVersion(v)
+		if err != nil {
+			continue // Not a version directory jvt understands
+		}
+
+		matched := true
+		for i := 0; i < count; i++ {
+			if parts[i] != want[i] {
+				matched = false
+				break
+			}
+		}
+		if matched && build && parts[3] != want[3] {
+			matched = false
+		}
+
+		if matched {
+			matches = append(matches, v)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		cmp, _ := CompareVersions(matches[i], matches[j])
+		return cmp > 0
+	})
+
+	return matches
+}
+
+// Resolve returns the newest installed version matching spec. It fails with an
+// AmbiguousVersionError if several matches are equally new.
+func (r *Resolver) Resolve(spec string) (string, error) {
+	matches := r.Matches(spec)
+	if len(matches) == 0 {
+		return "", &NotInstalledError{Spec: spec}
+	}
+
+	var tied []string
+	for _, m := range matches {
+		if cmp, _ := CompareVersions(m, matches[0]); cmp == 0 {
+			tied = append(tied, m)
+		}
+	}
+	if len(tied) > 1 {
+		return "", &AmbiguousVersionError{Spec: spec, Candidates: tied}
+	}
+
+	return matches[0], nil
+}
+
+// ResolveUnique returns the single installed version matching spec. It is meant
+// for destructive operations and fails with an AmbiguousVersionError whenever
+// spec matches more than one version.
+func (r *Resolver) ResolveUnique(spec string) (string, error) {
+	matches := r.Matches(spec)
+	switch len(matches) {
+	case 0:
+		return "", &NotInstalledError{Spec: spec}
+	case 1:
+		return matches[0], nil
+	default:
+		return "", &AmbiguousVersionError{Spec: spec, Candidates: matches}
+	}
+}
+
+// parseSpec parses a possibly partial version spec such as "17", "17.0.10" or
+// "17.0.10+7". It returns the parsed parts, how many of major/minor/patch were
+// given, and whether a build number was given.
+func parseSpec(spec string) ([4]int, int, bool, error) {
+	parts, err := parseVersion(spec)
+	if err != nil {
+		return parts, 0, false, err
+	}
+
+	mainPart, buildPart, hasBuild := strings.Cut(spec, "+")
+	fields := strings.Split(mainPart, ".")
+	if len(fields) > 3 {
+		return parts, 0, false, fmt.Errorf("invalid version format: %s", spec)
+	}
+	for _, f := range fields {
+		if _, err := strconv.Atoi(f); err != nil {
+			return parts, 0, false, fmt.Errorf("invalid version number: %s", f)
+		}
+	}
+
+	return parts, len(fields), hasBuild && buildPart != "", nil
+}
@@ -0,0 +1,121 @@
+package version
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// installed is the set of version directories the resolver tests run against
+var installed = []string{
+	"1.8.0",
+	"11.0.22+7",
+	"17.0.9+9",
+	"17.0.10+7",
+	"17.1.0+3",
+	"21.0.2+13",
+	"21.0.2+13-custom",
+	"not-a-version",
+}
+
+func TestResolverMatches(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{spec: "1", want: []string{"1.8.0"}},
+		{spec: "11", want: []string{"11.0.22+7"}},
+		{spec: "17", want: []string{"17.1.0+3", "17.0.10+7", "17.0.9+9"}},
+		{spec: "17.0", want: []string{"17.0.10+7", "17.0.9+9"}},
+		{spec: "17.0.9", want: []string{"17.0.9+9"}},
+		{spec: "17.0.10+7", want: []string{"17.0.10+7"}},
+		{spec: "17.0.10+8", want: nil},
+		{spec: "21.0.2+13-custom", want: []string{"21.0.2+13-custom"}},
+		{spec: "8", want: nil},
+		{spec: "abc", want: nil},
+		{spec: "1.2.3.4", want: nil},
+	}
+
+	r := NewResolver(installed)
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			if got := r.Matches(tt.spec); !slices.Equal(got, tt.want) {
+				t.Errorf("Matches(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverResolve(t *testing.T) {
+	tests := []struct {
+		name          string
+		installed     []string
+		spec          string
+		want          string
+		wantAmbiguous bool
+		wantMissing   bool
+	}{
+		{name: "legacy major is not a prefix of 11 or 17", installed: installed, spec: "1", want: "1.8.0"},
+		{name: "newest of a major", installed: installed, spec: "17", want: "17.1.0+3"},
+		{name: "newest of a minor", installed: installed, spec: "17.0", want: "17.0.10+7"},
+		{name: "exact directory name", installed: installed, spec: "21.0.2+13-custom", want: "21.0.2+13-custom"},
+		{name: "not installed", installed: installed, spec: "8", wantMissing: true},
+		{name: "equally new builds are ambiguous", installed: []string{"21.0.2", "21.0.2+0"}, spec: "21", wantAmbiguous: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewResolver(tt.installed).Resolve(tt.spec)
+			checkResolved(t, got, err, tt.want, tt.wantAmbiguous, tt.wantMissing)
+		})
+	}
+}
+
+func TestResolverResolveUnique(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		want          string
+		wantAmbiguous bool
+		wantMissing   bool
+	}{
+		{name: "single match", spec: "1", want: "1.8.0"},
+		{name: "full version", spec: "17.0.9+9", want: "17.0.9+9"},
+		{name: "several builds of a major", spec: "17", wantAmbiguous: true},
+		{name: "several builds of a minor", spec: "17.0", wantAmbiguous: true},
+		{name: "not installed", spec: "22", wantMissing: true},
+	}
+
+	r := NewResolver(installed)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.ResolveUnique(tt.spec)
+			checkResolved(t, got, err, tt.want, tt.wantAmbiguous, tt.wantMissing)
+		})
+	}
+}
+
+// checkResolved compares a resolved version and error with the expected outcome
+func checkResolved(t *testing.T, got string, err error, want string, wantAmbiguous, wantMissing bool) {
+	t.Helper()
+
+	var ambiguousErr *AmbiguousVersionError
+	var notInstalledErr *NotInstalledError
+	switch {
+	case wantAmbiguous:
+		if !errors.As(err, &ambiguousErr) {
+			t.Fatalf("got %q, %v; want an AmbiguousVersionError", got, err)
+		}
+		if len(ambiguousErr.Candidates) < 2 {
+			t.Errorf("ambiguity lists %v, want at least two candidates", ambiguousErr.Candidates)
+		}
+	case wantMissing:
+		if !errors.As(err, &notInstalledErr) {
+			t.Fatalf("got %q, %v; want a NotInstalledError", got, err)
+		}
+	default:
+		if err != nil || got != want {
+			t.Errorf("got %q, %v; want %q", got, err, want)
+		}
+	}
+}